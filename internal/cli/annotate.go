@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/spf13/cobra"
+)
+
+var (
+	annotateTitle   string
+	annotateNote    string
+	annotateSection string
+	annotateClear   bool
+)
+
+var annotateCmd = &cobra.Command{
+	Use:   "annotate <id|name> <step>",
+	Short: "Set a title, note or section heading on a selection step",
+	Long: `Annotate a step of a saved selection. Steps are numbered from 1 as shown by
+"cmdsetgo selections show". Titles and sections become headings in markdown
+exports and comment blocks in bash exports; notes become prose or comments.
+
+Pass an empty value (e.g. --note "") to remove a single annotation, or --clear
+to remove all of them. Without flags the step's current annotations are shown.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, selection, err := loadSelectionArg(args[:1])
+		if err != nil {
+			return err
+		}
+
+		step, err := strconv.Atoi(args[1])
+		if err != nil || step < 1 || step > len(selection.Items) {
+			return fmt.Errorf("invalid step %q: selection has %d steps", args[1], len(selection.Items))
+		}
+		item := &selection.Items[step-1]
+
+		flags := cmd.Flags()
+		if !flags.Changed("title") && !flags.Changed("note") && !flags.Changed("section") && !annotateClear {
+			printAnnotations(step, *item)
+			return nil
+		}
+
+		if annotateClear {
+			item.Title, item.Note, item.Section = "", "", ""
+		}
+		if flags.Changed("title") {
+			item.Title = annotateTitle
+		}
+		if flags.Changed("note") {
+			item.Note = annotateNote
+		}
+		if flags.Changed("section") {
+			item.Section = annotateSection
+		}
+
+		if err := pick.WriteSelection(path, selection); err != nil {
+			return err
+		}
+		printAnnotations(step, *item)
+		return nil
+	},
+}
+
+func printAnnotations(step int, item pick.Item) {
+	fmt.Printf("Step %d: %s\n", step, item.Cmd)
+	fmt.Printf("  Section: %s\n", orDash(item.Section))
+	fmt.Printf("  Title:   %s\n", orDash(item.Title))
+	fmt.Printf("  Note:    %s\n", orDash(item.Note))
+}
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+	annotateCmd.Flags().StringVar(&annotateTitle, "title", "", "Short heading for the step")
+	annotateCmd.Flags().StringVar(&annotateNote, "note", "", "Explanation shown before the step")
+	annotateCmd.Flags().StringVar(&annotateSection, "section", "", "Start a new section with this heading at the step")
+	annotateCmd.Flags().BoolVar(&annotateClear, "clear", false, "Remove all annotations from the step")
+}
@@ -19,9 +19,10 @@ view, pick, and export them as a clean bash script or markdown runbook.`,
 		}
 
 		if os.Getenv("CMDSETGO_EVENTS_PATH") == "" {
-			fmt.Println("Note: cmdsetgo hook is not active in this session.")
-			fmt.Println("Run `cmdsetgo install` to set it up, or restart your terminal if you just installed it.")
-			fmt.Println()
+			// Stderr keeps the note out of output that scripts capture.
+			fmt.Fprintln(os.Stderr, "Note: cmdsetgo hook is not active in this session.")
+			fmt.Fprintln(os.Stderr, "Run `cmdsetgo install` to set it up, or restart your terminal if you just installed it.")
+			fmt.Fprintln(os.Stderr)
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// eventCmd prints the details of a single event. It is used as the fzf
+// preview command, so it stays out of the help listing.
+var eventCmd = &cobra.Command{
+	Use:    "event <id>",
+	Short:  "Show details for a single recorded event",
+	Hidden: true,
+	Args:   cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventsPath, err := store.GetEventsPath()
+		if err != nil {
+			return err
+		}
+
+		allEvents, err := events.ReadEvents(eventsPath)
+		if err != nil {
+			return err
+		}
+
+		ev, ok := events.FindByID(allEvents, args[0])
+		if !ok {
+			return fmt.Errorf("event not found: %s", args[0])
+		}
+
+		duration := "-"
+		if ev.DurationMs > 0 {
+			duration = (time.Duration(ev.DurationMs) * time.Millisecond).String()
+		}
+		fmt.Printf("Command:  %s\n", ev.Cmd)
+		fmt.Printf("Cwd:      %s\n", ev.Cwd)
+		fmt.Printf("Exit:     %d\n", ev.Exit)
+		fmt.Printf("Duration: %s\n", duration)
+		fmt.Printf("Time:     %s\n", ev.Ts.Local().Format(time.RFC1123))
+		fmt.Printf("Shell:    %s (%s@%s)\n", ev.Shell, ev.User, ev.Host)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(eventCmd)
+}
@@ -1,13 +1,13 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+	"text/tabwriter"
+	"text/template"
 
 	"github.com/drakeafk/cmdsetgo/internal/export"
 	"github.com/drakeafk/cmdsetgo/internal/pick"
@@ -16,42 +16,106 @@ import (
 )
 
 var (
-	exportFormat    string
-	exportOut       string
-	exportSelection string
-	exportRedact    []string
+	exportFormat      string
+	exportOut         string
+	exportSelection   string
+	exportRedact      []string
+	exportEnable      []string
+	exportDisable     []string
+	exportParams      bool
+	exportTargets     string
+	exportMergeRuns   bool
+	exportReport      bool
+	exportReportFmt   string
+	exportFailSuspect bool
+	exportTemplate    string
+	exportList        bool
 )
 
 var exportCmd = &cobra.Command{
 	Use:   "export",
 	Short: "Export selected commands to a script or runbook",
+	Long: `Export a saved selection as a script, runbook, task file, CI pipeline,
+Dockerfile, notebook, recording or HTML page; --list-formats describes each
+format. Without --format, the format is inferred from the --out file name
+(run.sh, runbook.md, Makefile, ...) and defaults to bash.
+
+Any executable named cmdsetgo-export-<name> on PATH adds the format <name>: it
+receives the selection as JSON on stdin, with commands already redacted and
+parameterized and a "required" list of the variables they need, and writes the
+export to stdout.
+
+Parameters declared on the selection ("cmdsetgo selections param") are always
+hoisted into variables at the top of the export. With --params, repeated
+literals such as the repo root, home directory, user name and version strings
+are detected and hoisted too.
+
+Secrets are redacted in every format (see "cmdsetgo redact"). --redaction-report
+writes a sidecar listing which rule redacted which byte span of each step, and
+--fail-on-suspect refuses to export while random-looking strings remain.
+
+--template renders the selection with a Go text/template, from a file or
+~/.cmdsetgo/templates/<name>.tmpl, instead of a built-in format.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportList {
+			return listFormats()
+		}
+
 		stateDir, err := store.GetStateDir()
 		if err != nil {
 			return err
 		}
 
-		selectionPath := exportSelection
-		if selectionPath == "" {
-			// Find most recent selection
-			selectionPath, err = findMostRecentSelection(stateDir)
-			if err != nil {
-				return err
-			}
-		} else if !filepath.IsAbs(selectionPath) && !strings.Contains(selectionPath, string(filepath.Separator)) {
-			// If it's just an ID, assume it's in stateDir
-			selectionPath = filepath.Join(stateDir, fmt.Sprintf("selection-%s.json", selectionPath))
+		selectionPath, err := pick.ResolveSelection(stateDir, exportSelection)
+		if err != nil {
+			return err
 		}
 
-		file, err := os.Open(selectionPath)
+		selection, err := pick.LoadSelection(selectionPath)
 		if err != nil {
-			return fmt.Errorf("failed to open selection file %s: %w", selectionPath, err)
+			return err
+		}
+
+		redactor, err := loadRedactor(selection.RepoRoot, exportEnable, exportDisable, exportRedact)
+		if err != nil {
+			return err
+		}
+
+		opts := export.Options{Redactor: redactor, Params: selection.Params, MergeRuns: exportMergeRuns, Warnings: os.Stderr}
+		if exportParams {
+			opts.Params = append(opts.Params, export.DetectParams(selection, opts)...)
+		}
+
+		switch exportTargets {
+		case "step":
+		case "cwd":
+			opts.GroupByCwd = true
+		default:
+			return fmt.Errorf("unknown --targets %q (want step or cwd)", exportTargets)
+		}
+
+		if exportReportFmt != "json" && exportReportFmt != "text" {
+			return fmt.Errorf("unknown redaction report format: %s (want json or text)", exportReportFmt)
+		}
+		var report export.RedactionReport
+		if exportReport || exportFailSuspect {
+			report = export.BuildRedactionReport(selection, opts)
+		}
+		if exportFailSuspect && len(report.Suspects) > 0 {
+			for _, s := range report.Suspects {
+				fmt.Fprintf(os.Stderr, "step %d: bytes %d-%d look like a secret\n", s.Step, s.Start, s.End)
+			}
+			return fmt.Errorf("%d suspected secret(s) not redacted; add a rule or allowlist entry, or --redact-enable high-entropy", len(report.Suspects))
 		}
-		defer file.Close()
 
-		var selection pick.Selection
-		if err := json.NewDecoder(file).Decode(&selection); err != nil {
-			return fmt.Errorf("failed to decode selection file: %w", err)
+		var tmpl *template.Template
+		var format export.Format
+		if exportTemplate != "" {
+			if tmpl, err = loadExportTemplate(exportTemplate); err != nil {
+				return err
+			}
+		} else if format, err = resolveFormat(cmd.Flags().Changed("format")); err != nil {
+			return err
 		}
 
 		var out io.Writer = os.Stdout
@@ -64,46 +128,115 @@ var exportCmd = &cobra.Command{
 			out = f
 		}
 
-		switch exportFormat {
-		case "bash":
-			return export.BashExporter(out, selection, exportRedact)
-		case "md", "markdown":
-			return export.MarkdownExporter(out, selection, exportRedact)
-		default:
-			return fmt.Errorf("unknown format: %s", exportFormat)
+		if tmpl != nil {
+			err = export.TemplateExporter(out, tmpl, selection, opts)
+		} else {
+			err = format.Exporter.Export(out, selection, opts)
+		}
+		if err != nil || !exportReport {
+			return err
 		}
+		return writeRedactionReport(exportOut, exportReportFmt, report)
 	},
 }
 
-func findMostRecentSelection(dir string) (string, error) {
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		return "", err
+// resolveFormat returns the --format format, or the one matching the --out
+// file name if --format was not given explicitly.
+func resolveFormat(explicit bool) (export.Format, error) {
+	if !explicit && exportOut != "" {
+		if f, ok := export.FormatForPath(exportOut); ok {
+			return f, nil
+		}
 	}
+	return export.Lookup(exportFormat)
+}
 
-	var selections []string
-	for _, f := range files {
-		if !f.IsDir() && strings.HasPrefix(f.Name(), "selection-") && strings.HasSuffix(f.Name(), ".json") {
-			selections = append(selections, f.Name())
+// listFormats prints the built-in formats and the external exporters on
+// PATH.
+func listFormats() error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tFILE\tDESCRIPTION")
+	for _, f := range export.Formats() {
+		name := f.Name
+		if len(f.Aliases) > 0 {
+			name += " (" + strings.Join(f.Aliases, ", ") + ")"
 		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, orDash(f.Ext), f.Description)
 	}
+	for _, f := range export.ExternalFormats() {
+		fmt.Fprintf(w, "%s\t-\t%s (%s)\n", f.Name, f.Description, f.Path)
+	}
+	return w.Flush()
+}
 
-	if len(selections) == 0 {
-		return "", fmt.Errorf("no selections found in %s", dir)
+// loadExportTemplate parses the template at path, or else the named template
+// in the config directory or built into cmdsetgo.
+func loadExportTemplate(path string) (*template.Template, error) {
+	name := strings.TrimSuffix(filepath.Base(path), export.TemplateExt)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !strings.ContainsRune(path, filepath.Separator) {
+		configDir, cerr := store.GetConfigDir()
+		if cerr != nil {
+			return nil, cerr
+		}
+		data, err = os.ReadFile(filepath.Join(configDir, "templates", name+export.TemplateExt))
+		if os.IsNotExist(err) {
+			var src string
+			if src, err = export.ShippedTemplate(name); err == nil {
+				data = []byte(src)
+			} else {
+				err = fmt.Errorf("template %q not found in %s or built in (%s)", path,
+					filepath.Join(configDir, "templates"), strings.Join(export.ShippedTemplates(), ", "))
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := export.ParseTemplate(name, string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
 	}
+	return tmpl, nil
+}
 
-	// Sort descending (most recent first)
-	sort.Slice(selections, func(i, j int) bool {
-		return selections[i] > selections[j]
-	})
+// writeRedactionReport writes report in format ("json" or "text") next to
+// the export at out, or to stderr if the export went to stdout.
+func writeRedactionReport(out, format string, report export.RedactionReport) error {
+	write, ext := report.WriteText, ".txt"
+	if format == "json" {
+		write, ext = report.WriteJSON, ".json"
+	}
+	if out == "" {
+		return write(os.Stderr)
+	}
 
-	return filepath.Join(dir, selections[0]), nil
+	path := out + ".redactions" + ext
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := write(f); err != nil {
+		return fmt.Errorf("write redaction report: %w", err)
+	}
+	return f.Close()
 }
 
 func init() {
 	rootCmd.AddCommand(exportCmd)
-	exportCmd.Flags().StringVar(&exportFormat, "format", "bash", "Output format: bash or md")
+	exportCmd.Flags().StringVar(&exportFormat, "format", "bash", "Output format (see --list-formats); inferred from --out when not set")
+	exportCmd.Flags().BoolVar(&exportList, "list-formats", false, "List built-in and external output formats and exit")
+	exportCmd.Flags().StringVar(&exportTemplate, "template", "", "Render with a template file or named template in ~/.cmdsetgo/templates (overrides --format)")
 	exportCmd.Flags().StringVar(&exportOut, "out", "", "Output file path (default stdout)")
-	exportCmd.Flags().StringVar(&exportSelection, "selection", "", "Selection ID or path to selection file")
+	exportCmd.Flags().StringVar(&exportSelection, "selection", "", "Selection ID, name or path to selection file (default most recent)")
 	exportCmd.Flags().StringSliceVar(&exportRedact, "redact-regex", []string{}, "Custom regex patterns to redact")
+	exportCmd.Flags().StringSliceVar(&exportEnable, "redact-enable", nil, "Turn on redaction rules that are off by default (see \"cmdsetgo redact rules\")")
+	exportCmd.Flags().StringSliceVar(&exportDisable, "redact-disable", nil, "Turn off built-in redaction rules by name")
+	exportCmd.Flags().BoolVar(&exportParams, "params", false, "Hoist repeated literals (repo root, home, user, versions) into variables")
+	exportCmd.Flags().StringVar(&exportTargets, "targets", "step", "Make targets or just recipes per step or per cwd group: step or cwd")
+	exportCmd.Flags().BoolVar(&exportMergeRuns, "merge-runs", false, "Dockerfile: join consecutive steps into one RUN layer with &&")
+	exportCmd.Flags().BoolVar(&exportReport, "redaction-report", false, "Write which rules redacted which byte spans to <out>.redactions.<ext>")
+	exportCmd.Flags().StringVar(&exportReportFmt, "redaction-report-format", "json", "Redaction report format: json or text")
+	exportCmd.Flags().BoolVar(&exportFailSuspect, "fail-on-suspect", false, "Refuse to export if random-looking strings remain unredacted")
 }
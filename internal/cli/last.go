@@ -16,6 +16,7 @@ var (
 	lastNum    int
 	lastScope  string
 	lastFormat string
+	lastIDs    bool
 )
 
 var lastCmd = &cobra.Command{
@@ -55,7 +56,7 @@ var lastCmd = &cobra.Command{
 			return printJSON(filtered)
 		}
 
-		printTable(filtered, repoRoot)
+		printTable(filtered, repoRoot, lastIDs)
 		return nil
 	},
 }
@@ -71,20 +72,35 @@ func printJSON(evs []events.CmdEvent) error {
 	return encoder.Encode(evs)
 }
 
-func printTable(evs []events.CmdEvent, repoRoot string) {
+// printTable prints the numbered event table, with each event's ID after
+// the number if showIDs is set (for use with `pick --ids`).
+func printTable(evs []events.CmdEvent, repoRoot string, showIDs bool) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
 	for i, ev := range evs {
+		fmt.Fprintf(w, "# %d\t", i+1)
+		if showIDs {
+			fmt.Fprintf(w, "%s\t", ev.ID())
+		}
 		formattedTime := ev.Ts.Local().Format("15:04:05")
 		shortCwd := scope.FormatCwd(ev.Cwd, repoRoot)
-		fmt.Fprintf(w, "# %d\t%s\t%s\t%s\t(%d)\n", i+1, formattedTime, shortCwd, ev.Cmd, ev.Exit)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", formattedTime, shortCwd, ev.Label(), exitLabel(ev))
 	}
 	w.Flush()
 }
 
+// exitLabel formats the exit code column; notes and markers have none.
+func exitLabel(ev events.CmdEvent) string {
+	if !ev.IsCommand() {
+		return ""
+	}
+	return fmt.Sprintf("(%d)", ev.Exit)
+}
+
 func init() {
 	rootCmd.AddCommand(lastCmd)
 	lastCmd.Flags().IntVarP(&lastNum, "num", "n", 30, "Number of commands to show")
 	lastCmd.Flags().StringVar(&lastScope, "scope", "", "Scope: repo or global (default auto-detect)")
 	lastCmd.Flags().StringVar(&lastFormat, "format", "table", "Output format: table or json")
+	lastCmd.Flags().BoolVar(&lastIDs, "show-ids", false, "Show event IDs in the table")
 }
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/shell"
+	"github.com/drakeafk/cmdsetgo/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note <text>...",
+	Short: "Record a note in the command log",
+	Long: `Record a free-form note alongside your commands. Notes show up in "last",
+can be picked like commands, and are exported as prose in markdown runbooks
+and as comments in bash scripts.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeAnnotationEvent(events.TypeNote, strings.Join(args, " "))
+	},
+}
+
+var markCmd = &cobra.Command{
+	Use:   "mark <label>",
+	Short: "Record a named marker in the command log",
+	Long: `Record a marker, e.g. "cmdsetgo mark start-debug". Markers can be used as
+range boundaries when picking: "cmdsetgo pick --since-marker start-debug".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return writeAnnotationEvent(events.TypeMarker, args[0])
+	},
+}
+
+// writeAnnotationEvent appends a note or marker event for the current
+// directory to the events log.
+func writeAnnotationEvent(eventType, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("%s text cannot be empty", eventType)
+	}
+
+	eventsPath, err := store.GetEventsPath()
+	if err != nil {
+		return err
+	}
+	if err := store.EnsureDirs(); err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	host, _ := os.Hostname()
+
+	event := events.CmdEvent{
+		Type:  eventType,
+		Ts:    time.Now(),
+		Shell: shell.DetectShell(),
+		Host:  host,
+		User:  os.Getenv("USER"),
+		Cwd:   cwd,
+		Cmd:   text,
+	}
+	if err := events.WriteEvent(eventsPath, event); err != nil {
+		return err
+	}
+
+	fmt.Printf("Recorded %s: %s\n", eventType, text)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(noteCmd)
+	rootCmd.AddCommand(markCmd)
+}
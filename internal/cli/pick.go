@@ -5,13 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
-	"time"
+	"os/exec"
+	"strings"
 
 	"github.com/drakeafk/cmdsetgo/internal/events"
 	"github.com/drakeafk/cmdsetgo/internal/pick"
 	"github.com/drakeafk/cmdsetgo/internal/scope"
+	"github.com/drakeafk/cmdsetgo/internal/shell"
 	"github.com/drakeafk/cmdsetgo/internal/store"
+	"github.com/drakeafk/cmdsetgo/internal/tui"
 	"github.com/spf13/cobra"
 )
 
@@ -20,115 +22,295 @@ var (
 	pickScope     string
 	excludeCommon bool
 	excludeRegex  []string
+	pickTUI       bool
+	pickFZF       bool
+
+	pickSelect         string
+	pickIDs            []string
+	pickMatch          string
+	pickLastSuccessful int
+	pickJSON           bool
+
+	pickSinceMarker string
+	pickUntilMarker string
+
+	pickName        string
+	pickDescription string
+	pickTags        []string
 )
 
 var pickCmd = &cobra.Command{
 	Use:   "pick",
 	Short: "Interactively pick and reorder commands",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		eventsPath, err := store.GetEventsPath()
+		selectedItems, repoRoot, err := chooseEvents(cmd)
 		if err != nil {
 			return err
 		}
 
-		allEvents, err := events.ReadEvents(eventsPath)
+		if isNonInteractivePick(cmd) {
+			return saveNonInteractivePick(selectedItems, repoRoot)
+		}
+
+		if len(selectedItems) == 0 {
+			fmt.Println("No commands selected.")
+			return nil
+		}
+
+		selection, err := saveSelection(selectedItems, repoRoot)
 		if err != nil {
 			return err
 		}
 
-		var repoRoot string
-		if pickScope == "repo" || (pickScope == "" && isInGitRepo()) {
-			repoRoot, err = scope.GetGitRepoRoot()
-			if err != nil {
-				return err
-			}
+		ref := selection.ID
+		if selection.Name != "" {
+			ref = selection.Name
 		}
+		fmt.Printf("\nSaved selection: %s\n", ref)
+		fmt.Printf("Export: cmdsetgo export --selection %s --format bash --out run.sh\n", ref)
 
-		filtered := scope.FilterEventsByRepoScope(allEvents, repoRoot)
+		return nil
+	},
+}
 
-		patterns := excludeRegex
-		if excludeCommon {
-			patterns = append(patterns, pick.CommonExclusions...)
-		}
-		filtered = pick.FilterExclusions(filtered, patterns)
+// chooseEvents loads the pick candidates and lets the user choose among them,
+// either from the non-interactive flags or with the configured picker. It is
+// shared by `pick` and `selection append`, which register the same flags.
+func chooseEvents(cmd *cobra.Command) ([]pick.Item, string, error) {
+	filtered, repoRoot, err := loadPickCandidates()
+	if err != nil {
+		return nil, "", err
+	}
 
-		// Take last N
-		if len(filtered) > pickNum {
-			filtered = filtered[len(filtered)-pickNum:]
-		}
+	var selectedEvents []events.CmdEvent
+	if isNonInteractivePick(cmd) {
+		selectedEvents, err = pickFromFlags(filtered)
+		return pick.NewItems(selectedEvents), repoRoot, err
+	}
 
-		if len(filtered) == 0 {
-			fmt.Println("No commands found in this scope.")
-			return nil
-		}
+	if len(filtered) == 0 {
+		fmt.Println("No commands found in this scope.")
+		return nil, repoRoot, nil
+	}
 
-		// Print list
-		printTable(filtered, repoRoot)
+	if pickFZF {
+		selectedEvents, err = fzfSelection(filtered, repoRoot)
+	} else if pickTUI && tui.Available() {
+		// Only the full-screen picker can annotate steps.
+		items, err := tui.Run(filtered, repoRoot)
+		return items, repoRoot, err
+	} else {
+		selectedEvents, err = promptSelection(filtered, repoRoot)
+	}
+	return pick.NewItems(selectedEvents), repoRoot, err
+}
 
-		fmt.Print("\nSelect commands in the order you want (e.g. \"5 2 3\", \"1-4 7\", or \"all\"): ")
-		scanner := bufio.NewScanner(os.Stdin)
-		if !scanner.Scan() {
-			return nil
-		}
-		input := scanner.Text()
+func isNonInteractivePick(cmd *cobra.Command) bool {
+	flags := cmd.Flags()
+	return flags.Changed("select") || flags.Changed("ids") || flags.Changed("match") || flags.Changed("last-successful")
+}
 
-		indices, err := pick.ParseSelection(input, len(filtered))
-		if err != nil {
-			return err
+// pickFromFlags applies whichever of --select, --ids, --match or
+// --last-successful was given.
+func pickFromFlags(filtered []events.CmdEvent) ([]events.CmdEvent, error) {
+	var selectedItems []events.CmdEvent
+	var err error
+	switch {
+	case pickSelect != "":
+		var indices []int
+		indices, err = pick.ParseSelection(pickSelect, len(filtered))
+		selectedItems = pick.SelectIndices(filtered, indices)
+	case len(pickIDs) > 0:
+		// IDs are explicit, so look them up in the whole log rather than the
+		// filtered candidate list.
+		var allEvents []events.CmdEvent
+		allEvents, err = readEventLog()
+		if err == nil {
+			selectedItems, err = pick.SelectByIDs(allEvents, pickIDs)
 		}
+	case pickMatch != "":
+		selectedItems, err = pick.SelectByMatch(filtered, pickMatch)
+	case pickLastSuccessful > 0:
+		selectedItems = pick.SelectLastSuccessful(filtered, pickLastSuccessful)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		if len(indices) == 0 {
-			fmt.Println("No commands selected.")
-			return nil
-		}
+	if len(selectedItems) == 0 {
+		return nil, fmt.Errorf("no commands selected")
+	}
+	return selectedItems, nil
+}
 
-		var selectedItems []events.CmdEvent
-		for _, idx := range indices {
-			selectedItems = append(selectedItems, filtered[idx-1])
-		}
+// saveNonInteractivePick saves the selection and prints only its ID (or the
+// selection as JSON) so it can be used from scripts.
+func saveNonInteractivePick(selectedItems []pick.Item, repoRoot string) error {
+	selection, err := saveSelection(selectedItems, repoRoot)
+	if err != nil {
+		return err
+	}
 
-		// Save selection
-		selectionID := time.Now().Format("20060102-150405")
-		selection := pick.Selection{
-			ID:        selectionID,
-			CreatedAt: time.Now().Format(time.RFC3339),
-			Scope:     pickScope,
-			RepoRoot:  repoRoot,
-			Items:     selectedItems,
-		}
+	if pickJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(selection)
+	}
+	fmt.Println(selection.ID)
+	return nil
+}
+
+// loadPickCandidates reads the event log and applies the scope, exclusion
+// and --num filters shared by all pick modes.
+func loadPickCandidates() ([]events.CmdEvent, string, error) {
+	allEvents, err := readEventLog()
+	if err != nil {
+		return nil, "", err
+	}
 
-		stateDir, err := store.GetStateDir()
+	var repoRoot string
+	if pickScope == "repo" || (pickScope == "" && isInGitRepo()) {
+		repoRoot, err = scope.GetGitRepoRoot()
 		if err != nil {
-			return err
-		}
-		if err := os.MkdirAll(stateDir, 0755); err != nil {
-			return err
+			return nil, "", err
 		}
+	}
+
+	filtered := scope.FilterEventsByRepoScope(allEvents, repoRoot)
 
-		selectionPath := filepath.Join(stateDir, fmt.Sprintf("selection-%s.json", selectionID))
-		file, err := os.Create(selectionPath)
+	if pickSinceMarker != "" || pickUntilMarker != "" {
+		filtered, err = pick.SliceByMarkers(filtered, pickSinceMarker, pickUntilMarker)
 		if err != nil {
-			return err
+			return nil, "", err
 		}
-		defer file.Close()
+	}
 
-		encoder := json.NewEncoder(file)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(selection); err != nil {
-			return err
+	patterns := excludeRegex
+	if excludeCommon {
+		patterns = append(patterns, pick.CommonExclusions...)
+	}
+	filtered = pick.FilterExclusions(filtered, patterns)
+
+	// Take last N
+	if len(filtered) > pickNum {
+		filtered = filtered[len(filtered)-pickNum:]
+	}
+
+	return filtered, repoRoot, nil
+}
+
+func readEventLog() ([]events.CmdEvent, error) {
+	eventsPath, err := store.GetEventsPath()
+	if err != nil {
+		return nil, err
+	}
+	return events.ReadEvents(eventsPath)
+}
+
+// promptSelection prints the candidate table and reads index syntax from stdin.
+func promptSelection(filtered []events.CmdEvent, repoRoot string) ([]events.CmdEvent, error) {
+	printTable(filtered, repoRoot, false)
+
+	fmt.Print("\nSelect commands in the order you want (e.g. \"5 2 3\", \"1-4 7\", or \"all\"): ")
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return nil, nil
+	}
+	input := scanner.Text()
+
+	indices, err := pick.ParseSelection(input, len(filtered))
+	if err != nil {
+		return nil, err
+	}
+
+	return pick.SelectIndices(filtered, indices), nil
+}
+
+// fzfSelection pipes the candidates through `fzf --multi`, using the hidden
+// `event` command for the preview window. If fzf is not installed it falls
+// back to the index prompt.
+func fzfSelection(filtered []events.CmdEvent, repoRoot string) ([]events.CmdEvent, error) {
+	fzfPath, err := exec.LookPath("fzf")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fzf not found on PATH; falling back to the index prompt.")
+		return promptSelection(filtered, repoRoot)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		self = "cmdsetgo"
+	}
+
+	fzf := exec.Command(fzfPath,
+		"--multi",
+		"--delimiter", "\t",
+		"--with-nth", "2..",
+		"--tac",
+		"--preview", shell.Quote(self)+" event {1}",
+		"--preview-window", "down:7:wrap",
+		"--header", "tab: select  enter: save (selection order is kept)",
+	)
+	fzf.Stdin = strings.NewReader(strings.Join(pick.FzfLines(filtered), "\n"))
+	fzf.Stderr = os.Stderr
+	out, err := fzf.Output()
+	if err != nil {
+		// 1 means no match and 130 means the user aborted; neither is an error.
+		if exitErr, ok := err.(*exec.ExitError); ok && (exitErr.ExitCode() == 1 || exitErr.ExitCode() == 130) {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("fzf failed: %w", err)
+	}
 
-		fmt.Printf("\nSaved selection: %s\n", selectionID)
-		fmt.Printf("Export: cmdsetgo export --selection %s --format bash --out run.sh\n", selectionID)
+	return pick.ParseFzfOutput(string(out), filtered)
+}
 
-		return nil
-	},
+// saveSelection writes the chosen items to the state directory and returns
+// the saved selection.
+func saveSelection(selectedItems []pick.Item, repoRoot string) (pick.Selection, error) {
+	selection := pick.Selection{
+		Name:        pickName,
+		Description: pickDescription,
+		Tags:        pickTags,
+		Scope:       pickScope,
+		RepoRoot:    repoRoot,
+		Items:       selectedItems,
+	}
+
+	stateDir, err := store.GetStateDir()
+	if err != nil {
+		return pick.Selection{}, err
+	}
+
+	if err := pick.SaveNewSelection(stateDir, &selection); err != nil {
+		return pick.Selection{}, err
+	}
+	return selection, nil
+}
+
+// addPickFlags registers the candidate filtering and picker flags on cmd.
+// The flags share their variables, so chooseEvents works for any command
+// they are registered on.
+func addPickFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&pickNum, "num", "n", 50, "Number of commands to show")
+	cmd.Flags().StringVar(&pickScope, "scope", "", "Scope: repo or global (default auto-detect)")
+	cmd.Flags().BoolVar(&excludeCommon, "exclude-common", true, "Exclude common noise commands like ls, cd, etc.")
+	cmd.Flags().StringSliceVar(&excludeRegex, "exclude-regex", []string{}, "Regex patterns to exclude commands")
+	cmd.Flags().BoolVar(&pickTUI, "tui", true, "Use the full-screen picker when running in a terminal (--tui=false for the index prompt)")
+	cmd.Flags().BoolVar(&pickFZF, "fzf", false, "Pick with an external fzf --multi (falls back to the index prompt if fzf is missing)")
+	cmd.Flags().StringVar(&pickSelect, "select", "", "Select by index without prompting (e.g. \"3-7 9\")")
+	cmd.Flags().StringSliceVar(&pickIDs, "ids", []string{}, "Select events by ID without prompting (see `last --show-ids`)")
+	cmd.Flags().StringVar(&pickMatch, "match", "", "Select every command matching a regex without prompting")
+	cmd.Flags().IntVar(&pickLastSuccessful, "last-successful", 0, "Select the last N commands that exited 0 without prompting")
+	cmd.Flags().StringVar(&pickSinceMarker, "since-marker", "", "Only offer commands recorded after the latest marker with this label")
+	cmd.Flags().StringVar(&pickUntilMarker, "until-marker", "", "Only offer commands recorded before this marker (after --since-marker)")
+	cmd.MarkFlagsMutuallyExclusive("select", "ids", "match", "last-successful")
 }
 
 func init() {
 	rootCmd.AddCommand(pickCmd)
-	pickCmd.Flags().IntVarP(&pickNum, "num", "n", 50, "Number of commands to show")
-	pickCmd.Flags().StringVar(&pickScope, "scope", "", "Scope: repo or global (default auto-detect)")
-	pickCmd.Flags().BoolVar(&excludeCommon, "exclude-common", true, "Exclude common noise commands like ls, cd, etc.")
-	pickCmd.Flags().StringSliceVar(&excludeRegex, "exclude-regex", []string{}, "Regex patterns to exclude commands")
+	addPickFlags(pickCmd)
+	pickCmd.Flags().BoolVar(&pickJSON, "json", false, "Print the saved selection as JSON (non-interactive mode)")
+	pickCmd.Flags().StringVar(&pickName, "name", "", "Name for the saved selection (usable wherever a selection ID is)")
+	pickCmd.Flags().StringVar(&pickDescription, "description", "", "Description stored with the selection")
+	pickCmd.Flags().StringSliceVar(&pickTags, "tag", []string{}, "Tags stored with the selection (repeatable)")
 }
@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/redact"
+	"github.com/drakeafk/cmdsetgo/internal/runner"
+	"github.com/drakeafk/cmdsetgo/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeMatch  string
+	purgeIDs    []string
+	purgeSince  string
+	purgeRedact bool
+	purgeDryRun bool
+)
+
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Delete or redact recorded events, e.g. a leaked secret",
+	Long: `Remove events from the command log, its rotated segments (events.jsonl.1, ...),
+saved selections and the logs of "cmdsetgo run" replays. Events are chosen by --match (a regular expression on the
+command), --id (as shown by "cmdsetgo redact scan" or the picker) and --since
+(a duration such as 2h, or a date or time); given several, an event must match
+all of them.
+
+By default matching events are deleted. With --redact they are kept and the
+--match text, plus anything the redaction rules find, is replaced with
+***REDACTED***.
+
+Each file is rewritten atomically and the original is kept next to it as
+<file>.bak-<time>. The backups still hold the purged data: delete them once
+you have checked the result. Commands recorded by the shell hook while the
+purge runs are kept.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if purgeMatch == "" && len(purgeIDs) == 0 && purgeSince == "" {
+			return fmt.Errorf("choose events with --match, --id or --since")
+		}
+		edit, err := purgeEdit()
+		if err != nil {
+			return err
+		}
+
+		eventsPath, err := store.GetEventsPath()
+		if err != nil {
+			return err
+		}
+		segments, err := events.Segments(eventsPath)
+		if err != nil {
+			return err
+		}
+
+		var results []events.RewriteResult
+		for _, path := range segments {
+			result, err := events.Rewrite(path, edit, purgeDryRun)
+			if err != nil {
+				return fmt.Errorf("purge %s: %w", path, err)
+			}
+			results = append(results, result)
+		}
+
+		stateDir, err := store.GetStateDir()
+		if err != nil {
+			return err
+		}
+		selectionResults, err := purgeSelections(stateDir, edit, purgeDryRun)
+		if err != nil {
+			return err
+		}
+		results = append(results, selectionResults...)
+		logResults, err := purgeRunLogs(runner.RunsDir(stateDir), edit, purgeDryRun)
+		if err != nil {
+			return err
+		}
+		results = append(results, logResults...)
+
+		return printPurgeSummary(results)
+	},
+}
+
+// purgeEdit builds the edit applied to every event from the flags.
+func purgeEdit() (events.Edit, error) {
+	var match *regexp.Regexp
+	var redactor *redact.Redactor
+	if purgeMatch != "" {
+		var err error
+		if match, err = regexp.Compile(purgeMatch); err != nil {
+			return nil, fmt.Errorf("invalid --match: %w", err)
+		}
+	}
+	if purgeRedact {
+		var custom []string
+		if purgeMatch != "" {
+			custom = []string{purgeMatch}
+		}
+		var err error
+		if redactor, err = loadRedactor("", nil, nil, custom); err != nil {
+			return nil, err
+		}
+	}
+
+	var since time.Time
+	if purgeSince != "" {
+		var err error
+		if since, err = parseSince(purgeSince, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(ev *events.CmdEvent) bool {
+		switch {
+		case match != nil && !match.MatchString(ev.Cmd):
+			return true
+		case len(purgeIDs) > 0 && !contains(purgeIDs, ev.ID()):
+			return true
+		case !since.IsZero() && ev.Ts.Before(since):
+			return true
+		}
+		if redactor == nil {
+			return false
+		}
+		ev.Cmd = redactor.Redact(ev.Cmd)
+		return true
+	}, nil
+}
+
+// parseSince accepts a duration before now (e.g. "2h") or a date or time.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a duration such as 2h or a date such as 2006-01-02", s)
+}
+
+// purgeSelections applies edit to the steps of every saved selection,
+// backing up and rewriting those that change.
+func purgeSelections(stateDir string, edit events.Edit, dryRun bool) ([]events.RewriteResult, error) {
+	selections, err := pick.ListSelections(stateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []events.RewriteResult
+	for _, selection := range selections {
+		path := pick.SelectionPath(stateDir, selection.ID)
+		result := events.RewriteResult{Path: path, Events: len(selection.Items)}
+		var kept []pick.Item
+		for _, item := range selection.Items {
+			before := item.CmdEvent
+			if !edit(&item.CmdEvent) {
+				result.Deleted++
+				continue
+			}
+			if item.CmdEvent != before {
+				result.Changed++
+			}
+			kept = append(kept, item)
+		}
+		if !result.Modified() {
+			continue
+		}
+		if !dryRun {
+			result.Backup = events.BackupPath(path)
+			if err := copyFile(path, result.Backup); err != nil {
+				return results, fmt.Errorf("back up %s: %w", path, err)
+			}
+			selection.Items = kept
+			if err := pick.WriteSelection(path, selection); err != nil {
+				return results, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// purgeRunLogs applies edit to the steps of every run log, backing up and
+// rewriting those that change.
+func purgeRunLogs(dir string, edit events.Edit, dryRun bool) ([]events.RewriteResult, error) {
+	logs, err := runner.ListLogs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []events.RewriteResult
+	for _, log := range logs {
+		path := runner.LogPath(dir, log.ID)
+		result := events.RewriteResult{Path: path, Events: len(log.Steps)}
+		var kept []runner.StepResult
+		for _, step := range log.Steps {
+			before := step.CmdEvent
+			if !edit(&step.CmdEvent) {
+				result.Deleted++
+				continue
+			}
+			if step.CmdEvent != before {
+				result.Changed++
+			}
+			kept = append(kept, step)
+		}
+		if !result.Modified() {
+			continue
+		}
+		if !dryRun {
+			result.Backup = events.BackupPath(path)
+			if err := copyFile(path, result.Backup); err != nil {
+				return results, fmt.Errorf("back up %s: %w", path, err)
+			}
+			log.Steps = kept
+			if err := runner.WriteLog(path, log); err != nil {
+				return results, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0600)
+}
+
+func printPurgeSummary(results []events.RewriteResult) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tEVENTS\tCHANGED\tDELETED")
+	changed, deleted, malformed := 0, 0, 0
+	var backups []string
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", r.Path, r.Events, r.Changed, r.Deleted)
+		changed += r.Changed
+		deleted += r.Deleted
+		malformed += r.Malformed
+		if r.Backup != "" {
+			backups = append(backups, r.Backup)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	verb := "Purged"
+	if purgeDryRun {
+		verb = "Would purge"
+	}
+	fmt.Printf("\n%s %d records: %d redacted, %d deleted.\n", verb, changed+deleted, changed, deleted)
+	if malformed > 0 {
+		fmt.Printf("%d malformed log lines were left untouched; check them by hand.\n", malformed)
+	}
+	if len(backups) > 0 {
+		fmt.Printf("\nBackups (these still contain the purged data):\n  %s\n", strings.Join(backups, "\n  "))
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(purgeCmd)
+	purgeCmd.Flags().StringVar(&purgeMatch, "match", "", "Regular expression matched against commands")
+	purgeCmd.Flags().StringSliceVar(&purgeIDs, "id", nil, "Event IDs to purge")
+	purgeCmd.Flags().StringVar(&purgeSince, "since", "", "Only events recorded after this duration ago (e.g. 2h) or date")
+	purgeCmd.Flags().BoolVar(&purgeRedact, "redact", false, "Redact matching events in place instead of deleting them")
+	purgeCmd.Flags().BoolVar(&purgeDryRun, "dry-run", false, "Show what would change without writing anything")
+}
@@ -0,0 +1,255 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/runner"
+	"github.com/drakeafk/cmdsetgo/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runRoot      string
+	runFrom      int
+	runDryRun    bool
+	runConfirm   bool
+	runKeepGoing bool
+	runShell     string
+
+	runVerify            bool
+	runJUnit             string
+	runDurationTolerance float64
+	runDurationSlack     time.Duration
+
+	runSandbox bool
+	runKeep    bool
+	runPassEnv []string
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [id|name]",
+	Short: "Replay a selection's commands in order (default most recent)",
+	Long: `Replay a saved selection. Each command runs in its recorded directory (or the
+same path under --root, which also replaces the repo root where commands
+mention it) with output streamed to the terminal. The run stops at the first
+failing step unless --keep-going is set, and a run log with each step's
+status, exit code and duration is saved under the state directory.
+
+Each step runs in its own shell, so "cd" and "export" do not carry over
+between steps.
+
+With --verify, every step runs (as with --keep-going) and its exit code and
+duration are compared against the recorded event. A pass/fail report is
+printed, optionally written as JUnit XML with --junit, and the command fails
+if any step no longer behaves as recorded.
+
+With --sandbox, the selection's repo root is first copied into a temporary
+directory (a git worktree of HEAD when it is a repository) and every step runs
+in the copy, with paths into the repo root rewritten and a scrubbed
+environment. The sandbox is removed afterwards unless --keep is set.`,
+	Args: cobra.MaximumNArgs(1),
+	// A failing step is not a usage error.
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := runTolerance().Validate(); err != nil {
+			return fmt.Errorf("--duration-tolerance/--duration-slack: %w", err)
+		}
+		_, selection, err := loadSelectionArg(args)
+		if err != nil {
+			return err
+		}
+
+		opts := runner.Options{
+			Root:      runRoot,
+			From:      runFrom,
+			DryRun:    runDryRun,
+			KeepGoing: runKeepGoing,
+			Shell:     runShell,
+			Stdin:     os.Stdin,
+			Stdout:    os.Stdout,
+			Stderr:    os.Stderr,
+		}
+		if runVerify {
+			if runDryRun {
+				return fmt.Errorf("--verify cannot be combined with --dry-run")
+			}
+			opts.KeepGoing = true
+		}
+		if runSandbox {
+			sb, err := prepareSandbox(selection)
+			if err != nil {
+				return err
+			}
+			defer finishSandbox(sb)
+			opts.Root = sb.Root
+			if opts.Env, err = sb.Env(os.Environ(), runPassEnv); err != nil {
+				return err
+			}
+		}
+		if runConfirm {
+			opts.Confirm = confirmStep(bufio.NewReader(os.Stdin))
+			// The prompt owns stdin; steps must not compete for it.
+			opts.Stdin = nil
+		}
+
+		log, runErr := runner.Run(selection, opts)
+		if runDryRun {
+			return runErr
+		}
+
+		stateDir, err := store.GetStateDir()
+		if err != nil {
+			return err
+		}
+		logPath, err := runner.SaveLog(runner.RunsDir(stateDir), &log)
+		if err != nil {
+			return err
+		}
+
+		printRunSummary(log, logPath)
+		if runErr != nil {
+			return runErr
+		}
+		if runVerify {
+			return verifyRun(selection, log)
+		}
+		if failed, ok := log.Failed(); ok {
+			return fmt.Errorf("step %d failed with exit code %d", failed.Step, failed.Exit)
+		}
+		return nil
+	},
+}
+
+// confirmStep returns a runner.Options.Confirm callback that asks on stdin.
+func confirmStep(in *bufio.Reader) func(int, pick.Item, string) (runner.Decision, error) {
+	return func(step int, item pick.Item, cwd string) (runner.Decision, error) {
+		for {
+			fmt.Printf("Run step %d? [Y/n/q] ", step)
+			line, err := in.ReadString('\n')
+			if err != nil && line == "" {
+				return runner.DecisionAbort, nil
+			}
+			switch strings.ToLower(strings.TrimSpace(line)) {
+			case "", "y", "yes":
+				return runner.DecisionRun, nil
+			case "n", "no", "s", "skip":
+				return runner.DecisionSkip, nil
+			case "q", "quit":
+				return runner.DecisionAbort, nil
+			}
+		}
+	}
+}
+
+// prepareSandbox creates the sandbox for --sandbox, refusing selections with
+// steps that would run outside it.
+func prepareSandbox(selection pick.Selection) (*runner.Sandbox, error) {
+	if runRoot != "" {
+		return nil, fmt.Errorf("--sandbox cannot be combined with --root")
+	}
+	if selection.RepoRoot == "" {
+		return nil, fmt.Errorf("selection %s has no repo root; --sandbox needs one to copy", selection.ID)
+	}
+	if outside := runner.StepsOutside(selection); len(outside) > 0 {
+		return nil, fmt.Errorf("step(s) %v run outside %s and cannot be sandboxed", outside, selection.RepoRoot)
+	}
+
+	sb, err := runner.NewSandbox(selection.RepoRoot)
+	if err != nil {
+		return nil, err
+	}
+	kind := "copy"
+	if sb.Worktree {
+		kind = "git worktree"
+	}
+	fmt.Printf("Sandbox (%s): %s\n", kind, sb.Root)
+	return sb, nil
+}
+
+func finishSandbox(sb *runner.Sandbox) {
+	if runKeep {
+		fmt.Printf("Sandbox kept at %s\n", sb.Root)
+		if sb.Worktree {
+			fmt.Printf("Remove it with: rm -rf %s && git worktree prune\n", sb.Dir)
+		}
+		return
+	}
+	if err := sb.Cleanup(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove sandbox %s: %v\n", sb.Dir, err)
+	}
+}
+
+// runTolerance is the duration tolerance given by the --duration-* flags.
+func runTolerance() runner.Tolerance {
+	return runner.Tolerance{Factor: runDurationTolerance, Slack: runDurationSlack}
+}
+
+// verifyRun prints the verification report for a finished run, writes the
+// JUnit file if requested, and fails if any step diverged from its recording.
+func verifyRun(selection pick.Selection, log runner.Log) error {
+	report := runner.Verify(selection, log, runTolerance())
+
+	fmt.Println()
+	if err := report.WriteText(os.Stdout); err != nil {
+		return err
+	}
+
+	if runJUnit != "" {
+		f, err := os.Create(runJUnit)
+		if err != nil {
+			return err
+		}
+		if err := report.WriteJUnit(f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+		fmt.Printf("JUnit report: %s\n", runJUnit)
+	}
+
+	if n := report.Failures(); n > 0 {
+		return fmt.Errorf("%d step(s) failed verification", n)
+	}
+	return nil
+}
+
+func printRunSummary(log runner.Log, logPath string) {
+	counts := make(map[string]int)
+	for _, s := range log.Steps {
+		counts[s.Status]++
+	}
+	fmt.Println()
+	fmt.Printf("Run %s: %d ok, %d failed, %d skipped\n", log.ID, counts[runner.StatusOK], counts[runner.StatusFailed], counts[runner.StatusSkipped])
+	if failed, ok := log.Failed(); ok {
+		ref := log.SelectionID
+		if log.SelectionName != "" {
+			ref = log.SelectionName
+		}
+		fmt.Printf("Resume with: cmdsetgo run %s --from %d\n", ref, failed.Step)
+	}
+	fmt.Printf("Run log: %s\n", logPath)
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringVar(&runRoot, "root", "", "Run steps recorded under the selection's repo root in this directory instead")
+	runCmd.Flags().IntVar(&runFrom, "from", 1, "Start at this step number (resume after a failure)")
+	runCmd.Flags().BoolVar(&runDryRun, "dry-run", false, "Print the steps without running them")
+	runCmd.Flags().BoolVar(&runConfirm, "confirm", false, "Ask before running each step")
+	runCmd.Flags().BoolVar(&runKeepGoing, "keep-going", false, "Continue after a failing step")
+	runCmd.Flags().StringVar(&runShell, "shell", "bash", "Shell used to run each command")
+	runCmd.Flags().BoolVar(&runVerify, "verify", false, "Compare each step's exit code and duration against the recording")
+	runCmd.Flags().StringVar(&runJUnit, "junit", "", "With --verify, also write the report as JUnit XML to this file")
+	runCmd.Flags().Float64Var(&runDurationTolerance, "duration-tolerance", 0, "With --verify, fail steps that take more than this factor (at least 1) longer or shorter than recorded (0 disables)")
+	runCmd.Flags().DurationVar(&runDurationSlack, "duration-slack", time.Second, "With --verify, durations within this much of the recording always pass")
+	runCmd.Flags().BoolVar(&runSandbox, "sandbox", false, "Run in a temporary copy of the selection's repo root with a scrubbed environment")
+	runCmd.Flags().BoolVar(&runKeep, "keep", false, "With --sandbox, keep the sandbox for inspection instead of removing it")
+	runCmd.Flags().StringSliceVar(&runPassEnv, "pass-env", nil, "With --sandbox, also pass these environment variables through")
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeName        string
+	mergeDescription string
+)
+
+var selectionsEditCmd = &cobra.Command{
+	Use:   "edit [id|name]",
+	Short: "Edit a selection's steps in $EDITOR (default most recent)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, selection, err := loadSelectionArg(args)
+		if err != nil {
+			return err
+		}
+
+		tmp, err := os.CreateTemp("", "cmdsetgo-edit-*.txt")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+
+		original := pick.FormatEditDocument(selection)
+		doc := original
+		for {
+			if err := os.WriteFile(tmp.Name(), []byte(doc), 0600); err != nil {
+				return err
+			}
+			if err := runEditor(tmp.Name()); err != nil {
+				return err
+			}
+			edited, err := os.ReadFile(tmp.Name())
+			if err != nil {
+				return err
+			}
+			doc = string(edited)
+
+			if doc == original {
+				fmt.Println("No changes.")
+				return nil
+			}
+
+			items, err := pick.ParseEditDocument(doc, selection)
+			if err == nil {
+				selection.Items = items
+				break
+			}
+
+			// Leave the selection file alone and let the user fix their edit.
+			fmt.Fprintf(os.Stderr, "Invalid edit: %v\n", err)
+			if !confirm("Re-open the editor?") {
+				return fmt.Errorf("edit aborted; selection %s was not changed", selection.ID)
+			}
+		}
+
+		if err := pick.WriteSelection(path, selection); err != nil {
+			return err
+		}
+		fmt.Printf("Updated selection %s (%d steps)\n", selection.ID, len(selection.Items))
+		return nil
+	},
+}
+
+var selectionsAppendCmd = &cobra.Command{
+	Use:   "append <id|name>",
+	Short: "Pick more commands and append them to a selection",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, selection, err := loadSelectionArg(args)
+		if err != nil {
+			return err
+		}
+
+		selectedItems, _, err := chooseEvents(cmd)
+		if err != nil {
+			return err
+		}
+		if len(selectedItems) == 0 {
+			fmt.Println("No commands selected.")
+			return nil
+		}
+
+		selection.Items = append(selection.Items, selectedItems...)
+		if err := pick.WriteSelection(path, selection); err != nil {
+			return err
+		}
+		fmt.Printf("Appended %d steps to selection %s (%d steps)\n", len(selectedItems), selection.ID, len(selection.Items))
+		return nil
+	},
+}
+
+var selectionsMergeCmd = &cobra.Command{
+	Use:   "merge <id|name> <id|name>...",
+	Short: "Concatenate selections into a new selection",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var selections []pick.Selection
+		for _, ref := range args {
+			_, selection, err := loadSelectionArg([]string{ref})
+			if err != nil {
+				return err
+			}
+			selections = append(selections, selection)
+		}
+
+		merged := pick.MergeSelections(selections...)
+		merged.Name = mergeName
+		merged.Description = mergeDescription
+
+		stateDir, err := store.GetStateDir()
+		if err != nil {
+			return err
+		}
+		if err := pick.SaveNewSelection(stateDir, &merged); err != nil {
+			return err
+		}
+		fmt.Printf("Merged %d selections into %s (%d steps)\n", len(selections), merged.ID, len(merged.Items))
+		return nil
+	},
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, falling back to vi.
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// The editor variable may carry arguments (e.g. "code --wait").
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+// confirm asks a yes/no question on stdin, defaulting to yes.
+func confirm(question string) bool {
+	fmt.Printf("%s [Y/n] ", question)
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+func init() {
+	selectionsCmd.AddCommand(selectionsEditCmd, selectionsAppendCmd, selectionsMergeCmd)
+
+	addPickFlags(selectionsAppendCmd)
+
+	selectionsMergeCmd.Flags().StringVar(&mergeName, "name", "", "Name for the merged selection")
+	selectionsMergeCmd.Flags().StringVar(&mergeDescription, "description", "", "Description for the merged selection")
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/spf13/cobra"
+)
+
+var (
+	paramDescription string
+	paramRemove      bool
+)
+
+var selectionsParamCmd = &cobra.Command{
+	Use:   "param <id|name> [NAME=VALUE | NAME]",
+	Short: "Declare, remove or list a selection's export parameters",
+	Long: `Declare a parameter on a saved selection. Exports replace every occurrence of
+VALUE in the selection's commands and directories with ${NAME} and define
+NAME="${NAME:-VALUE}" at the top, so the value can be overridden from the
+environment.
+
+With --rm, the named parameter is removed. Without a parameter argument the
+selection's parameters are listed.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, selection, err := loadSelectionArg(args[:1])
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 1 {
+			if len(selection.Params) == 0 {
+				fmt.Println("No parameters declared.")
+				return nil
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tVALUE\tDESCRIPTION")
+			for _, p := range selection.Params {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Value, p.Description)
+			}
+			return w.Flush()
+		}
+
+		name, value, hasValue := strings.Cut(args[1], "=")
+		if err := pick.ValidateParamName(name); err != nil {
+			return err
+		}
+		index := -1
+		for i, p := range selection.Params {
+			if p.Name == name {
+				index = i
+			}
+		}
+
+		switch {
+		case paramRemove:
+			if index < 0 {
+				return fmt.Errorf("selection has no parameter %s", name)
+			}
+			selection.Params = append(selection.Params[:index], selection.Params[index+1:]...)
+		case !hasValue:
+			return fmt.Errorf("missing value: use %s=VALUE", name)
+		case value == "":
+			return fmt.Errorf("parameter %s needs a non-empty value to replace", name)
+		case index >= 0:
+			selection.Params[index].Value = value
+			if cmd.Flags().Changed("description") {
+				selection.Params[index].Description = paramDescription
+			}
+		default:
+			selection.Params = append(selection.Params, pick.Param{Name: name, Value: value, Description: paramDescription})
+		}
+
+		if err := pick.WriteSelection(path, selection); err != nil {
+			return err
+		}
+		if paramRemove {
+			fmt.Printf("Removed parameter %s\n", name)
+		} else {
+			fmt.Printf("Set parameter %s=%s\n", name, value)
+		}
+		return nil
+	},
+}
+
+func init() {
+	selectionsCmd.AddCommand(selectionsParamCmd)
+	selectionsParamCmd.Flags().StringVar(&paramDescription, "description", "", "Describe the parameter in exports")
+	selectionsParamCmd.Flags().BoolVar(&paramRemove, "rm", false, "Remove the parameter")
+}
@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/scope"
+	"github.com/drakeafk/cmdsetgo/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	selectionsFormat string
+	selectionsTag    string
+	copyDescription  string
+)
+
+var selectionsCmd = &cobra.Command{
+	Use:     "selections",
+	Aliases: []string{"selection"},
+	Short:   "List and manage saved selections",
+}
+
+var selectionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved selections",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stateDir, err := store.GetStateDir()
+		if err != nil {
+			return err
+		}
+
+		selections, err := pick.ListSelections(stateDir)
+		if err != nil {
+			return err
+		}
+
+		if selectionsTag != "" {
+			var tagged []pick.Selection
+			for _, s := range selections {
+				for _, tag := range s.Tags {
+					if tag == selectionsTag {
+						tagged = append(tagged, s)
+						break
+					}
+				}
+			}
+			selections = tagged
+		}
+
+		if selectionsFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(selections)
+		}
+
+		if len(selections) == 0 {
+			fmt.Println("No selections found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tCREATED\tSTEPS\tTAGS\tDESCRIPTION")
+		for _, s := range selections {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", s.ID, orDash(s.Name), s.CreatedAt, len(s.Items), orDash(strings.Join(s.Tags, ",")), s.Description)
+		}
+		return w.Flush()
+	},
+}
+
+var selectionsShowCmd = &cobra.Command{
+	Use:   "show [id|name]",
+	Short: "Show a selection's metadata and steps (default most recent)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, selection, err := loadSelectionArg(args)
+		if err != nil {
+			return err
+		}
+
+		if selectionsFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(selection)
+		}
+
+		fmt.Printf("ID:          %s\n", selection.ID)
+		fmt.Printf("Name:        %s\n", orDash(selection.Name))
+		fmt.Printf("Description: %s\n", orDash(selection.Description))
+		fmt.Printf("Tags:        %s\n", orDash(strings.Join(selection.Tags, ", ")))
+		fmt.Printf("Created:     %s\n", selection.CreatedAt)
+		fmt.Printf("Scope:       %s\n", orDash(selection.Scope))
+		fmt.Printf("Repo:        %s\n", orDash(selection.RepoRoot))
+		for _, p := range selection.Params {
+			fmt.Printf("Param:       %s=%s\n", p.Name, p.Value)
+		}
+		fmt.Println()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for i, item := range selection.Items {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, scope.FormatCwd(item.Cwd, selection.RepoRoot), item.Label(), exitLabel(item.CmdEvent))
+		}
+		return w.Flush()
+	},
+}
+
+var selectionsRenameCmd = &cobra.Command{
+	Use:   "rename <id|name> <new-name>",
+	Short: "Set or change a selection's name",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, selection, err := loadSelectionArg(args[:1])
+		if err != nil {
+			return err
+		}
+
+		newName := args[1]
+		if err := pick.ValidateName(newName); err != nil {
+			return err
+		}
+		stateDir, err := store.GetStateDir()
+		if err != nil {
+			return err
+		}
+		if existing, err := pick.FindSelectionByName(stateDir, newName); err == nil && existing != path {
+			return fmt.Errorf("a selection named %q already exists", newName)
+		}
+
+		selection.Name = newName
+		if err := pick.WriteSelection(path, selection); err != nil {
+			return err
+		}
+		fmt.Printf("Renamed selection %s to %s\n", selection.ID, newName)
+		return nil
+	},
+}
+
+var selectionsRmCmd = &cobra.Command{
+	Use:     "rm <id|name>...",
+	Aliases: []string{"delete"},
+	Short:   "Delete saved selections",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stateDir, err := store.GetStateDir()
+		if err != nil {
+			return err
+		}
+
+		// Resolve everything first so a typo doesn't leave a partial delete.
+		// A selection named twice (e.g. by name and ID) is deleted once.
+		var paths, refs []string
+		seen := make(map[string]bool)
+		for _, ref := range args {
+			path, err := pick.ResolveSelection(stateDir, ref)
+			if err != nil {
+				return err
+			}
+			key, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			paths = append(paths, path)
+			refs = append(refs, ref)
+		}
+
+		for i, path := range paths {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted selection %s\n", refs[i])
+		}
+		return nil
+	},
+}
+
+var selectionsCopyCmd = &cobra.Command{
+	Use:   "copy <id|name> [new-name]",
+	Short: "Copy a selection under a new ID (and optional name)",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, selection, err := loadSelectionArg(args[:1])
+		if err != nil {
+			return err
+		}
+
+		selection.Name = ""
+		if len(args) == 2 {
+			selection.Name = args[1]
+		}
+		if copyDescription != "" {
+			selection.Description = copyDescription
+		}
+
+		stateDir, err := store.GetStateDir()
+		if err != nil {
+			return err
+		}
+		if err := pick.SaveNewSelection(stateDir, &selection); err != nil {
+			return err
+		}
+		fmt.Printf("Copied selection to %s\n", selection.ID)
+		return nil
+	},
+}
+
+// loadSelectionArg resolves an optional selection reference argument and
+// loads it, returning the file path alongside the selection.
+func loadSelectionArg(args []string) (string, pick.Selection, error) {
+	stateDir, err := store.GetStateDir()
+	if err != nil {
+		return "", pick.Selection{}, err
+	}
+
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	path, err := pick.ResolveSelection(stateDir, ref)
+	if err != nil {
+		return "", pick.Selection{}, err
+	}
+
+	selection, err := pick.LoadSelection(path)
+	return path, selection, err
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	rootCmd.AddCommand(selectionsCmd)
+	selectionsCmd.AddCommand(selectionsListCmd, selectionsShowCmd, selectionsRenameCmd, selectionsRmCmd, selectionsCopyCmd)
+
+	selectionsListCmd.Flags().StringVar(&selectionsFormat, "format", "table", "Output format: table or json")
+	selectionsListCmd.Flags().StringVar(&selectionsTag, "tag", "", "Only list selections with this tag")
+	selectionsShowCmd.Flags().StringVar(&selectionsFormat, "format", "table", "Output format: table or json")
+	selectionsCopyCmd.Flags().StringVar(&copyDescription, "description", "", "Description for the copy")
+}
@@ -1,6 +1,18 @@
 package events
 
-import "time"
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"time"
+)
+
+// Event types. Notes and markers are written by `cmdsetgo note` and
+// `cmdsetgo mark`; for them Cmd holds the note text or marker label.
+const (
+	TypeCmd    = "cmd"
+	TypeNote   = "note"
+	TypeMarker = "marker"
+)
 
 type CmdEvent struct {
 	Type       string    `json:"type"`
@@ -13,3 +25,46 @@ type CmdEvent struct {
 	Exit       int       `json:"exit"`
 	DurationMs int64     `json:"duration_ms,omitempty"`
 }
+
+// IsCommand reports whether the event is a shell command rather than a
+// note or marker. Events without a type predate notes and are commands.
+func (e CmdEvent) IsCommand() bool {
+	return e.Type == "" || e.Type == TypeCmd
+}
+
+// Label returns the text shown for the event in listings: the command
+// itself, or the note text or marker label with a distinguishing prefix.
+func (e CmdEvent) Label() string {
+	switch e.Type {
+	case TypeNote:
+		return "# " + e.Cmd
+	case TypeMarker:
+		return "== " + e.Cmd + " =="
+	}
+	return e.Cmd
+}
+
+// ID returns a short identifier derived from the event's timestamp, host,
+// directory and command. It is stable across reads of the log, so it can be
+// used to refer to an event from the command line.
+func (e CmdEvent) ID() string {
+	h := sha1.New()
+	h.Write([]byte(e.Ts.UTC().Format(time.RFC3339Nano)))
+	h.Write([]byte{0})
+	h.Write([]byte(e.Host))
+	h.Write([]byte{0})
+	h.Write([]byte(e.Cwd))
+	h.Write([]byte{0})
+	h.Write([]byte(e.Cmd))
+	return hex.EncodeToString(h.Sum(nil))[:10]
+}
+
+// FindByID returns the event with the given ID.
+func FindByID(evs []CmdEvent, id string) (CmdEvent, bool) {
+	for _, ev := range evs {
+		if ev.ID() == id {
+			return ev, true
+		}
+	}
+	return CmdEvent{}, false
+}
@@ -0,0 +1,220 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Edit changes an event in place and reports whether to keep it.
+type Edit func(ev *CmdEvent) (keep bool)
+
+// RewriteResult summarises the rewrite of one log file.
+type RewriteResult struct {
+	Path string
+	// Events is the number of events read, Changed and Deleted how many of
+	// them were edited or removed.
+	Events, Changed, Deleted int
+	// Malformed lines cannot be parsed and are kept unchanged.
+	Malformed int
+	// Backup is the copy of the original file, or "" if nothing changed or
+	// this was a dry run.
+	Backup string
+}
+
+// Modified reports whether the rewrite changed any events.
+func (r RewriteResult) Modified() bool {
+	return r.Changed > 0 || r.Deleted > 0
+}
+
+// How long Rewrite waits for a hook to finish writing a partial line.
+const (
+	partialLineWait  = 20 * time.Millisecond
+	partialLineTries = 10
+)
+
+// Rewrite applies edit to every event in the JSONL log at path and atomically
+// replaces the file with the result, keeping the original as a backup named
+// path.bak-<time>. Unchanged lines are copied byte for byte. With dryRun set
+// only the counts are computed.
+//
+// Hooks append to the log without locking, so Rewrite keeps reading until
+// the file stops growing, and after the replacement moves any line that
+// still reached the old file over to the new one.
+func Rewrite(path string, edit Edit, dryRun bool) (RewriteResult, error) {
+	result := RewriteResult{Path: path}
+	src, err := os.Open(path)
+	if err != nil {
+		return result, err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return result, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".events-*.tmp")
+	if err != nil {
+		return result, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	offset, err := rewriteFrom(src, 0, tmp, edit, &result)
+	if err != nil {
+		return result, err
+	}
+	if dryRun || !result.Modified() {
+		return result, nil
+	}
+
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		return result, err
+	}
+	if err := tmp.Sync(); err != nil {
+		return result, err
+	}
+	if err := tmp.Close(); err != nil {
+		return result, err
+	}
+
+	// The backup is a second name for the original file, so appends that
+	// race with the rename below land in it rather than being lost.
+	backup := BackupPath(path)
+	if err := os.Link(path, backup); err != nil {
+		return result, fmt.Errorf("back up %s: %w", path, err)
+	}
+	result.Backup = backup
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(backup)
+		result.Backup = ""
+		return result, err
+	}
+
+	// src is still open on the original, now the backup.
+	dst, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return result, err
+	}
+	defer dst.Close()
+	if _, err := rewriteFrom(src, offset, dst, edit, &result); err != nil {
+		return result, fmt.Errorf("copy events appended during the rewrite: %w", err)
+	}
+	return result, dst.Close()
+}
+
+// rewriteFrom edits the lines of src from offset until it stops growing,
+// writing them to dst, and returns the offset reached.
+func rewriteFrom(src *os.File, offset int64, dst io.Writer, edit Edit, result *RewriteResult) (int64, error) {
+	var pending []byte
+	for tries := 0; ; {
+		chunk, err := io.ReadAll(io.NewSectionReader(src, offset+int64(len(pending)), 1<<62))
+		if err != nil {
+			return offset, err
+		}
+		pending = append(pending, chunk...)
+
+		end := bytes.LastIndexByte(pending, '\n') + 1
+		if end == 0 {
+			if len(pending) == 0 {
+				return offset, nil
+			}
+			// A hook may be half way through a line; give it time to finish.
+			if tries++; tries < partialLineTries {
+				time.Sleep(partialLineWait)
+				continue
+			}
+			end = len(pending)
+		}
+
+		for _, line := range bytes.SplitAfter(pending[:end], []byte("\n")) {
+			if len(line) == 0 {
+				continue
+			}
+			out, err := rewriteLine(line, edit, result)
+			if err != nil {
+				return offset, err
+			}
+			if _, err := dst.Write(out); err != nil {
+				return offset, err
+			}
+		}
+		offset += int64(end)
+		pending = pending[end:]
+		tries = 0
+	}
+}
+
+func rewriteLine(line []byte, edit Edit, result *RewriteResult) ([]byte, error) {
+	var ev CmdEvent
+	if err := json.Unmarshal(line, &ev); err != nil {
+		if len(bytes.TrimSpace(line)) > 0 {
+			result.Malformed++
+		}
+		return line, nil
+	}
+
+	result.Events++
+	before := ev
+	if !edit(&ev) {
+		result.Deleted++
+		return nil, nil
+	}
+	if ev == before {
+		return line, nil
+	}
+	result.Changed++
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// BackupPath returns an unused name for a backup of path taken now.
+func BackupPath(path string) string {
+	base := path + ".bak-" + time.Now().Format("20060102-150405")
+	backup := base
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(backup); os.IsNotExist(err) {
+			return backup
+		}
+		backup = fmt.Sprintf("%s_%d", base, n)
+	}
+}
+
+// rotatedSuffix matches rotated log segments such as events.jsonl.1.
+var rotatedSuffix = regexp.MustCompile(`^\.[0-9]+$`)
+
+// Segments returns the log at path followed by its rotated segments
+// (path.1, path.2, ...) that exist, newest first.
+func Segments(path string) ([]string, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var rotated []string
+	for _, m := range matches {
+		if rotatedSuffix.MatchString(m[len(path):]) {
+			rotated = append(rotated, m)
+		}
+	}
+	sort.Slice(rotated, func(i, j int) bool {
+		if len(rotated[i]) != len(rotated[j]) {
+			return len(rotated[i]) < len(rotated[j])
+		}
+		return rotated[i] < rotated[j]
+	})
+
+	segments := []string{path}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		segments = nil
+	}
+	return append(segments, rotated...), nil
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testLog = `{"type":"cmd","ts":"2026-01-02T10:00:00Z","cwd":"/repo","cmd":"ls","exit":0}
+{"type":"cmd","ts":"2026-01-02T10:01:00+01:00","cwd":"/repo","cmd":"export TOKEN=hunter2","exit":0}
+not json
+{"type":"cmd","ts":"2026-01-02T10:02:00Z","cwd":"/repo","cmd":"curl -u me:hunter2 example.com","exit":0}
+`
+
+func dropSecrets(ev *CmdEvent) bool {
+	if strings.HasPrefix(ev.Cmd, "export") {
+		return false
+	}
+	ev.Cmd = strings.ReplaceAll(ev.Cmd, "hunter2", "***")
+	return true
+}
+
+func TestRewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte(testLog), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Rewrite(path, dropSecrets, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Events != 3 || result.Changed != 1 || result.Deleted != 1 || result.Malformed != 1 {
+		t.Errorf("result = %+v", result)
+	}
+
+	data, _ := os.ReadFile(path)
+	got := string(data)
+	if strings.Contains(got, "hunter2") {
+		t.Errorf("log still holds the secret:\n%s", got)
+	}
+	// Untouched lines are copied byte for byte.
+	if !strings.HasPrefix(got, strings.SplitAfter(testLog, "\n")[0]) || !strings.Contains(got, "not json\n") {
+		t.Errorf("log = %s", got)
+	}
+
+	backup, _ := os.ReadFile(result.Backup)
+	if string(backup) != testLog {
+		t.Errorf("backup = %q, want the original", backup)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestRewriteDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte(testLog), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Rewrite(path, dropSecrets, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Modified() || result.Backup != "" {
+		t.Errorf("result = %+v", result)
+	}
+	if data, _ := os.ReadFile(path); string(data) != testLog {
+		t.Errorf("dry run changed the log:\n%s", data)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("dry run left files behind: %v", entries)
+	}
+}
+
+func TestRewriteKeepsAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte(testLog), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a hook appending while the first lines are being edited.
+	appended := false
+	edit := func(ev *CmdEvent) bool {
+		if !appended {
+			appended = true
+			if err := WriteEvent(path, CmdEvent{Type: TypeCmd, Cmd: "make test"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dropSecrets(ev)
+	}
+	if _, err := Rewrite(path, edit, false); err != nil {
+		t.Fatal(err)
+	}
+
+	evs, err := ReadEvents(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 3 || evs[2].Cmd != "make test" {
+		t.Errorf("events = %+v, want the appended event kept", evs)
+	}
+}
+
+func TestSegments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "events.jsonl")
+	for _, name := range []string{"events.jsonl", "events.jsonl.10", "events.jsonl.2", "events.jsonl.bak-20260101-000000"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := Segments(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{path, path + ".2", path + ".10"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Segments = %v, want %v", got, want)
+	}
+}
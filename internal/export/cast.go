@@ -0,0 +1,181 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+const (
+	// castIdleLimit caps every pause in a cast: time spent running a
+	// command, and the gap before the next one.
+	castIdleLimit = 2 * time.Second
+	// castPause is used between steps whose timestamps are unknown.
+	castPause = 500 * time.Millisecond
+	// castKeystroke is the typing speed, sped up so that no command takes
+	// longer than castMaxTyping to type.
+	castKeystroke = 50 * time.Millisecond
+	castMaxTyping = 2 * time.Second
+)
+
+// castHeader is the first line of an asciicast v2 file.
+type castHeader struct {
+	Version       int               `json:"version"`
+	Width         int               `json:"width"`
+	Height        int               `json:"height"`
+	Timestamp     int64             `json:"timestamp,omitempty"`
+	IdleTimeLimit float64           `json:"idle_time_limit"`
+	Title         string            `json:"title,omitempty"`
+	Env           map[string]string `json:"env"`
+}
+
+// castWriter writes asciicast v2 output events on a running clock.
+type castWriter struct {
+	w   io.Writer
+	now time.Duration
+	err error
+}
+
+func (c *castWriter) output(s string) {
+	if c.err != nil || s == "" {
+		return
+	}
+	data, err := json.Marshal(s)
+	if err == nil {
+		_, err = fmt.Fprintf(c.w, "[%.6f, \"o\", %s]\n", c.now.Seconds(), data)
+	}
+	c.err = err
+}
+
+func (c *castWriter) wait(d time.Duration) {
+	c.now += min(max(d, 0), castIdleLimit)
+}
+
+// typeText writes s one character at a time, with continuation prompts
+// after newlines.
+func (c *castWriter) typeText(s string) {
+	delay := castKeystroke
+	if n := utf8.RuneCountInString(s); n > 0 && time.Duration(n)*delay > castMaxTyping {
+		delay = castMaxTyping / time.Duration(n)
+	}
+	for _, r := range s {
+		if r == '\n' {
+			c.output("\r\n> ")
+		} else {
+			c.output(string(r))
+		}
+		c.now += delay
+	}
+}
+
+// CastExporter generates an asciicast v2 recording (for asciinema) that
+// types each command at a prompt showing its directory. Pauses follow the
+// recorded timestamps and durations, with idle time capped at two seconds.
+// No output was recorded, so a failing step only shows its exit code.
+func CastExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	data := newTemplateData(selection, opts)
+
+	header := castHeader{
+		Version:       2,
+		Width:         80,
+		Height:        24,
+		IdleTimeLimit: castIdleLimit.Seconds(),
+		Title:         selection.Name,
+		Env:           map[string]string{"SHELL": "/bin/bash", "TERM": "xterm-256color"},
+	}
+	for _, step := range data.Steps {
+		if !step.Ts.IsZero() && header.Timestamp == 0 {
+			header.Timestamp = step.Ts.Unix()
+		}
+		if step.IsCommand() {
+			for _, line := range strings.Split(castDir(selection, step.Cwd, opts)+" $ "+step.Command, "\n") {
+				header.Width = min(max(header.Width, utf8.RuneCountInString(line)+1), 160)
+			}
+		}
+	}
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", encoded); err != nil {
+		return err
+	}
+
+	c := &castWriter{w: w}
+	var last *events.CmdEvent
+	for _, step := range data.Steps {
+		if step.Section != "" {
+			c.output("\r\n" + castComment(step.Section))
+		}
+		switch step.Type {
+		case events.TypeNote:
+			c.output(castComment(step.Command))
+			c.wait(castPause)
+			continue
+		case events.TypeMarker:
+			c.output(fmt.Sprintf("\x1b[1m=== %s ===\x1b[0m\r\n", step.Command))
+			c.wait(castPause)
+			continue
+		}
+
+		if last != nil {
+			c.wait(castGap(*last, step.CmdEvent))
+		}
+		if step.Title != "" {
+			c.output(castComment(step.Title))
+		}
+		c.output(castPrompt(castDir(selection, step.Cwd, opts)))
+		c.wait(castPause)
+		c.typeText(step.Command)
+		c.output("\r\n")
+		c.wait(time.Duration(step.DurationMs) * time.Millisecond)
+		if step.Exit != 0 {
+			c.output(fmt.Sprintf("\x1b[31m[exit %d]\x1b[0m\r\n", step.Exit))
+		}
+		last = &step.CmdEvent
+	}
+	c.wait(castPause)
+	if last != nil {
+		c.output(castPrompt(castDir(selection, last.Cwd, opts)))
+	}
+	return c.err
+}
+
+// castGap returns the idle time between two steps: from the end of prev to
+// the start of next. Without a recorded duration the timestamps are taken as
+// they are.
+func castGap(prev, next events.CmdEvent) time.Duration {
+	if prev.Ts.IsZero() || next.Ts.IsZero() {
+		return castPause
+	}
+	return next.Ts.Sub(prev.Ts) - time.Duration(prev.DurationMs)*time.Millisecond
+}
+
+// castDir returns how the prompt shows dir: relative to the repository,
+// e.g. "app/web", when inside it.
+func castDir(selection pick.Selection, dir string, opts Options) string {
+	if rel := relativeCwd(selection.RepoRoot, dir); selection.RepoRoot != "" && !path.IsAbs(rel) {
+		return path.Join(path.Base(selection.RepoRoot), rel)
+	}
+	return substitute(dir, opts.Params)
+}
+
+func castPrompt(dir string) string {
+	return fmt.Sprintf("\x1b[1;34m%s\x1b[0m $ ", dir)
+}
+
+// castComment shows text as dim shell comment lines.
+func castComment(text string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(&b, "\x1b[2m# %s\x1b[0m\r\n", line)
+	}
+	return b.String()
+}
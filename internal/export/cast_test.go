@@ -0,0 +1,55 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCastExporter(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	selection := testSelection("/home/me/app",
+		cmdStep("/home/me/app", "make build"),
+		noteStep("token is GITHUB_TOKEN=ghp_one"),
+		cmdStep("/home/me/app/web", "npm test"),
+	)
+	selection.Name = "demo"
+	selection.Items[0].Ts, selection.Items[0].DurationMs = start, 30000
+	// An hour of idle time is compressed.
+	selection.Items[2].Ts, selection.Items[2].Exit = start.Add(time.Hour), 1
+
+	scanner := bufio.NewScanner(strings.NewReader(exportString(t, CastExporter, selection, Options{})))
+	scanner.Scan()
+	var header castHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		t.Fatal(err)
+	}
+	if header.Version != 2 || header.Timestamp != start.Unix() || header.Title != "demo" {
+		t.Errorf("header = %+v", header)
+	}
+
+	var screen strings.Builder
+	var last float64
+	for scanner.Scan() {
+		var event []any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+		at := event[0].(float64)
+		if at < last || at-last > castIdleLimit.Seconds()+castPause.Seconds() {
+			t.Errorf("event at %.2fs follows %.2fs", at, last)
+		}
+		last = at
+		screen.WriteString(event[2].(string))
+	}
+	if last > 15 {
+		t.Errorf("cast lasts %.1fs", last)
+	}
+	assertContains(t, screen.String(),
+		"app\x1b[0m $ make build\r\n",
+		"# token is GITHUB_TOKEN=***REDACTED***",
+		"app/web\x1b[0m $ npm test\r\n\x1b[31m[exit 1]",
+	)
+}
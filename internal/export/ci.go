@@ -0,0 +1,167 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// ciStep is one command of a CI job.
+type ciStep struct {
+	name string
+	// comments are the annotations and notes before the step.
+	comments []string
+	// dir is the step's directory relative to the repo root.
+	dir string
+	cmd string
+}
+
+// ciSteps turns the selection's commands into named CI steps. Notes,
+// markers and section headings become comments on the next step.
+func ciSteps(selection pick.Selection, opts Options) ([]ciStep, []string) {
+	selection = redactAnnotations(selection, opts)
+	cmds, required := redactCommands(selection, opts)
+	var steps []ciStep
+	var comments []string
+	for i, item := range selection.Items {
+		if item.Section != "" {
+			comments = append(comments, item.Section)
+		}
+		switch item.Type {
+		case events.TypeNote:
+			comments = append(comments, item.Cmd)
+			continue
+		case events.TypeMarker:
+			comments = append(comments, fmt.Sprintf("=== %s ===", item.Cmd))
+			continue
+		}
+		if item.Note != "" {
+			comments = append(comments, item.Note)
+		}
+
+		name := item.Title
+		if name == "" {
+			name = firstLineOf(cmds[i], 60)
+		}
+		steps = append(steps, ciStep{name: name, comments: comments, dir: relativeCwd(selection.RepoRoot, item.Cwd), cmd: cmds[i]})
+		comments = nil
+	}
+	return steps, required
+}
+
+// relativeCwd returns cwd relative to root, "." for the root itself, or cwd
+// unchanged if it is outside root (or root is unknown).
+func relativeCwd(root, cwd string) string {
+	if root == "" {
+		return cwd
+	}
+	rel, err := filepath.Rel(root, cwd)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return cwd
+	}
+	return filepath.ToSlash(rel)
+}
+
+// ciParams returns the params with the repo root replaced by the CI
+// checkout directory, given as a reference in the CI system's syntax.
+func ciParams(selection pick.Selection, params []pick.Param, checkout string) []pick.Param {
+	out := make([]pick.Param, len(params))
+	for i, p := range params {
+		out[i] = p
+		if selection.RepoRoot != "" && p.Value == selection.RepoRoot {
+			out[i].Value = checkout
+		}
+	}
+	return out
+}
+
+func firstLineOf(s string, max int) string {
+	line, _, more := strings.Cut(s, "\n")
+	if len(line) > max {
+		line, more = line[:max], true
+	}
+	if more {
+		line += " ..."
+	}
+	return line
+}
+
+// yamlString quotes s as a YAML double-quoted scalar, which accepts JSON
+// string syntax.
+func yamlString(s string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeYAMLValue writes s after prefix (e.g. "run:") as a literal block
+// scalar with its lines indented by indent, or quoted if it starts with a
+// space, which would break the block's indentation.
+func writeYAMLValue(w io.Writer, prefix, s, indent string) {
+	if s == "" || strings.HasPrefix(s, " ") {
+		fmt.Fprintf(w, "%s %s\n", prefix, yamlString(s))
+		return
+	}
+	fmt.Fprintf(w, "%s |\n", prefix)
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			fmt.Fprintln(w)
+			continue
+		}
+		fmt.Fprintf(w, "%s%s\n", indent, line)
+	}
+}
+
+// yamlLineBreaks are the line breaks YAML recognises; a comment must not
+// contain any of them.
+var yamlLineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// writeYAMLComments writes comments as YAML comments at indent, a comment
+// line for each of their lines.
+func writeYAMLComments(w io.Writer, comments []string, indent string) {
+	for _, c := range comments {
+		for _, line := range strings.Split(yamlLineBreaks.Replace(c), "\n") {
+			fmt.Fprintf(w, "%s# %s\n", indent, line)
+		}
+	}
+}
+
+// writeCIHeader writes the comment block at the top of a CI file.
+func writeCIHeader(w io.Writer, selection pick.Selection, opts Options) {
+	fmt.Fprintf(w, "# Generated by cmdsetgo at %s\n", time.Now().Format(time.RFC1123))
+	if selection.Name != "" {
+		fmt.Fprintf(w, "# Selection: %s\n", selection.Name)
+	}
+	if selection.Description != "" {
+		writeYAMLComments(w, []string{opts.redactor().Redact(selection.Description)}, "")
+	}
+	if selection.RepoRoot != "" {
+		fmt.Fprintf(w, "# Repo: %s\n", selection.RepoRoot)
+	}
+	fmt.Fprintln(w)
+}
+
+// ciJobName returns a job name derived from the selection name.
+func ciJobName(selection pick.Selection) string {
+	if name := taskSlug(selection.Name); name != "" {
+		return name
+	}
+	return "run"
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
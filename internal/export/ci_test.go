@@ -0,0 +1,115 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"gopkg.in/yaml.v3"
+)
+
+func ciSelection() pick.Selection {
+	selection := testSelection("/repo",
+		cmdStep("/repo", "export GITHUB_TOKEN=ghp_one"),
+		noteStep("build: the ${{ matrix }} is literal"),
+		cmdStep("/repo/web", "npm ci\nnpm run build -- --out \"/repo/dist\""),
+		markerStep("check\ninjected: marker"),
+		cmdStep("/repo/web", `  echo "${{ not.an.expression }}" | tee out: #x`),
+	)
+	selection.Name = "Release web"
+	// Multi-line annotations must stay inside their comments.
+	selection.Items[0].Section = "Setup\ninjected: section"
+	selection.Items[2].Title = "Build: web"
+	selection.Items[2].Note = "builds web\rinjected: note"
+	return selection
+}
+
+func TestGitHubActionsExporter(t *testing.T) {
+	opts := Options{Params: []pick.Param{{Name: "REPO_ROOT", Value: "/repo"}}}
+	out := exportString(t, GitHubActionsExporter, ciSelection(), opts)
+
+	var workflow struct {
+		Name string
+		Env  map[string]string
+		Jobs map[string]struct {
+			Env   map[string]string
+			Steps []struct {
+				Name             string
+				Uses             string
+				WorkingDirectory string `yaml:"working-directory"`
+				Run              string
+			}
+		}
+	}
+	if err := yaml.Unmarshal([]byte(out), &workflow); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, out)
+	}
+	assertNoInjectedKey(t, out)
+
+	job, ok := workflow.Jobs["release-web"]
+	if !ok || len(job.Steps) != 4 {
+		t.Fatalf("workflow = %+v\n%s", workflow, out)
+	}
+	if got := workflow.Env["REPO_ROOT"]; got != "${{ github.workspace }}" {
+		t.Errorf("REPO_ROOT = %q", got)
+	}
+	if got := job.Env["GITHUB_TOKEN"]; got != "${{ secrets.GITHUB_TOKEN }}" {
+		t.Errorf("GITHUB_TOKEN = %q", got)
+	}
+
+	build := job.Steps[2]
+	if build.Name != "Build: web" || build.WorkingDirectory != "web" {
+		t.Errorf("build step = %+v", build)
+	}
+	if want := "npm ci\nnpm run build -- --out \"${REPO_ROOT}/dist\"\n"; build.Run != want {
+		t.Errorf("run = %q, want %q", build.Run, want)
+	}
+	if want := `  echo "${{ '${{' }} not.an.expression }}" | tee out: #x`; job.Steps[3].Run != want {
+		t.Errorf("run = %q, want %q", job.Steps[3].Run, want)
+	}
+	if job.Steps[1].WorkingDirectory != "" {
+		t.Errorf("root step has working-directory %q", job.Steps[1].WorkingDirectory)
+	}
+	if strings.Contains(out, "ghp_") {
+		t.Errorf("workflow leaks a secret:\n%s", out)
+	}
+}
+
+func TestGitLabCIExporter(t *testing.T) {
+	out := exportString(t, GitLabCIExporter, ciSelection(), Options{})
+	var pipeline map[string]struct {
+		Script []string
+	}
+	if err := yaml.Unmarshal([]byte(out), &pipeline); err != nil {
+		t.Fatalf("invalid YAML: %v\n%s", err, out)
+	}
+	assertNoInjectedKey(t, out)
+	script := pipeline["release-web"].Script
+	for _, want := range []string{
+		"export GITHUB_TOKEN=\"${GITHUB_TOKEN:?must be set}\"\n",
+		`cd "$CI_PROJECT_DIR/web"`,
+		"npm ci\nnpm run build -- --out \"/repo/dist\"\n",
+		`  echo "${{ not.an.expression }}" | tee out: #x`,
+	} {
+		if !contains(script, want) {
+			t.Errorf("script missing %q:\n%q", want, script)
+		}
+	}
+	joined := strings.Join(script, "\n")
+	if n := strings.Count(joined, "section_start"); n != 3 {
+		t.Errorf("%d sections, want 3:\n%s", n, joined)
+	}
+}
+
+// assertNoInjectedKey fails if an annotation of ciSelection escaped its
+// comment and became a top-level key.
+func assertNoInjectedKey(t *testing.T, doc string) {
+	t.Helper()
+	var top map[string]any
+	if err := yaml.Unmarshal([]byte(doc), &top); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := top["injected"]; ok {
+		t.Errorf("annotation escaped its comment: injected: %v\n%s", v, doc)
+	}
+}
@@ -0,0 +1,209 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/redact"
+)
+
+// containerRoot is where the repository is copied in the image.
+const containerRoot = "/src"
+
+// dockerRun is one RUN instruction: one step's command, or several merged.
+type dockerRun struct {
+	cmds    []string
+	secrets []string
+}
+
+// DockerfileExporter generates a Dockerfile that copies the repository to
+// /src and replays the steps as RUN instructions, with a WORKDIR wherever the
+// directory changes. Paths under the repo root are rewritten to /src.
+//
+// Some shell state does not carry over between RUN instructions: a lone cd
+// is dropped in favour of WORKDIR, export NAME=value becomes ENV, and other
+// exports and source commands are kept with a warning. Redacted secrets are
+// mounted as BuildKit secrets into the steps that use them.
+func DockerfileExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	selection = redactAnnotations(selection, opts)
+	cmds, required := redactCommands(selection, opts)
+
+	fmt.Fprintln(w, "# syntax=docker/dockerfile:1")
+	fmt.Fprintf(w, "# Generated by cmdsetgo at %s\n", time.Now().Format(time.RFC1123))
+	if selection.Name != "" {
+		fmt.Fprintf(w, "# Selection: %s\n", selection.Name)
+	}
+	writeCommentLines(w, selection.Description)
+	if len(required) > 0 {
+		fmt.Fprintln(w, "# Secrets redacted from the recording; pass them with")
+		for _, name := range required {
+			fmt.Fprintf(w, "#   docker build --secret id=%s,env=%s ...\n", name, name)
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ARG BASE_IMAGE=ubuntu:24.04")
+	fmt.Fprintln(w, "FROM ${BASE_IMAGE}")
+	fmt.Fprintln(w, `SHELL ["/bin/bash", "-euo", "pipefail", "-c"]`)
+	fmt.Fprintln(w)
+
+	params := opts.Params
+	if selection.RepoRoot != "" {
+		params = ciParams(selection, params, containerRoot)
+	}
+	if len(params) > 0 {
+		fmt.Fprintln(w, "# Parameters (override with docker build --build-arg NAME=value)")
+		for _, p := range params {
+			writeCommentLines(w, p.Description)
+			fmt.Fprintf(w, "ARG %s=%s\n", p.Name, dockerQuote(p.Value))
+		}
+		fmt.Fprintln(w)
+	}
+
+	if selection.RepoRoot != "" {
+		fmt.Fprintf(w, "WORKDIR %s\n", containerRoot)
+		fmt.Fprintln(w, "COPY . .")
+	}
+
+	workdir := containerRoot
+	var pending *dockerRun
+	flush := func() {
+		if pending != nil {
+			writeDockerRun(w, *pending)
+			pending = nil
+		}
+	}
+	for i, item := range selection.Items {
+		if item.Section != "" {
+			flush()
+			fmt.Fprintln(w)
+			writeCommentLines(w, "--- "+item.Section)
+		}
+		switch item.Type {
+		case events.TypeNote:
+			flush()
+			writeCommentLines(w, item.Cmd)
+			continue
+		case events.TypeMarker:
+			flush()
+			writeCommentLines(w, fmt.Sprintf("=== %s ===", item.Cmd))
+			continue
+		}
+
+		cmd := cmds[i]
+		dir := substitute(item.Cwd, opts.Params)
+		if selection.RepoRoot != "" {
+			cmd = rebasePath(cmd, selection.RepoRoot, containerRoot)
+			if rel := relativeCwd(selection.RepoRoot, item.Cwd); !path.IsAbs(rel) {
+				dir = path.Join(containerRoot, rel)
+			} else {
+				opts.warnf("step %d runs in %s, outside the repository", i+1, item.Cwd)
+			}
+		}
+
+		if item.Title != "" || item.Note != "" {
+			flush()
+			writeCommentLines(w, item.Title)
+			writeCommentLines(w, item.Note)
+		}
+
+		words := redact.Tokenize(cmd)
+		switch {
+		case len(words) == 1 && words[0][0].Value == "cd":
+			flush()
+			writeCommentLines(w, cmd+" (the WORKDIR of later steps covers it)")
+			continue
+		case isPlainExport(words) && !strings.Contains(cmd, ":?must be set}"):
+			flush()
+			for _, word := range words[0][1:] {
+				name, value, _ := strings.Cut(word.Value, "=")
+				fmt.Fprintf(w, "ENV %s=%s\n", name, dockerQuote(value))
+			}
+			continue
+		}
+		for _, simple := range words {
+			if tool := simple[0].Value; tool == "source" || tool == "." || tool == "export" {
+				flush()
+				opts.warnf("step %d: %s only affects its own RUN instruction", i+1, tool)
+				fmt.Fprintf(w, "# WARNING: %s only affects this RUN instruction\n", tool)
+			}
+		}
+
+		if dir != workdir {
+			flush()
+			fmt.Fprintf(w, "WORKDIR %s\n", dir)
+			workdir = dir
+		}
+		if pending == nil || !opts.MergeRuns {
+			flush()
+			pending = &dockerRun{}
+		}
+		pending.cmds = append(pending.cmds, cmd)
+		for _, name := range required {
+			if strings.Contains(cmd, "${"+name+":?must be set}") && !contains(pending.secrets, name) {
+				pending.secrets = append(pending.secrets, name)
+			}
+		}
+	}
+	flush()
+	return nil
+}
+
+// writeDockerRun writes a RUN instruction, using a heredoc for multi-line
+// commands and joining merged single-line steps with &&.
+func writeDockerRun(w io.Writer, run dockerRun) {
+	fmt.Fprint(w, "RUN ")
+	for _, name := range run.secrets {
+		fmt.Fprintf(w, "--mount=type=secret,id=%s,env=%s ", name, name)
+	}
+	for _, cmd := range run.cmds {
+		if strings.Contains(cmd, "\n") {
+			fmt.Fprintf(w, "<<'CMDSETGO'\n%s\nCMDSETGO\n", strings.Join(run.cmds, "\n"))
+			return
+		}
+	}
+	fmt.Fprintln(w, strings.Join(run.cmds, " && \\\n    "))
+}
+
+// isPlainExport reports whether a command is nothing but
+// export NAME=value ..., which ENV can replace.
+func isPlainExport(words [][]redact.Token) bool {
+	if len(words) != 1 || len(words[0]) < 2 || words[0][0].Value != "export" {
+		return false
+	}
+	for _, word := range words[0][1:] {
+		name, _, ok := strings.Cut(word.Value, "=")
+		if !ok || pick.ValidateParamName(name) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// rebasePath replaces root where it appears as a whole path prefix in s.
+func rebasePath(s, root, target string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(s, root)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		j := i + len(root)
+		if atBoundary(s, i, j, root) && (j == len(s) || s[j] != '.') {
+			b.WriteString(s[:i] + target)
+		} else {
+			b.WriteString(s[:j])
+		}
+		s = s[j:]
+	}
+}
+
+// dockerQuote quotes a value for ENV and ARG.
+func dockerQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
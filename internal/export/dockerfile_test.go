@@ -0,0 +1,58 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+func dockerSelection() pick.Selection {
+	return testSelection("/home/me/app",
+		cmdStep("/home/me/app", "export GOFLAGS=-mod=mod CGO_ENABLED=0"),
+		cmdStep("/home/me/app", "go build -o /home/me/app/bin/app ./cmd/app"),
+		cmdStep("/home/me/app", "go test ./..."),
+		cmdStep("/home/me/app", "cd web"),
+		cmdStep("/home/me/app/web", "source .env && npm ci --token ghp_one"),
+		cmdStep("/home/me/app/web", "cat > x <<EOF\n$HOME\nEOF"),
+		cmdStep("/home/me/app/web", "cd \"../a\nb\""),
+	)
+}
+
+func TestDockerfileExporter(t *testing.T) {
+	var warnings bytes.Buffer
+	assertContains(t, exportString(t, DockerfileExporter, dockerSelection(), Options{Warnings: &warnings}),
+		"WORKDIR /src\nCOPY . .\n",
+		"ENV GOFLAGS=\"-mod=mod\"\nENV CGO_ENABLED=\"0\"\n",
+		"RUN go build -o /src/bin/app ./cmd/app\nRUN go test ./...\n",
+		"# cd web (the WORKDIR of later steps covers it)\n",
+		"# WARNING: source only affects this RUN instruction\nWORKDIR /src/web\n",
+		`RUN --mount=type=secret,id=TOKEN,env=TOKEN source .env && npm ci --token "${TOKEN:?must be set}"`,
+		"RUN <<'CMDSETGO'\ncat > x <<EOF\n$HOME\nEOF\nCMDSETGO\n",
+		"# cd \"../a\n# b\" (the WORKDIR of later steps covers it)\n",
+	)
+	if !strings.Contains(warnings.String(), "step 5: source") {
+		t.Errorf("warnings = %q", warnings.String())
+	}
+}
+
+func TestDockerfileExporterMergeRuns(t *testing.T) {
+	assertContains(t, exportString(t, DockerfileExporter, dockerSelection(), Options{MergeRuns: true}),
+		"RUN go build -o /src/bin/app ./cmd/app && \\\n    go test ./...\n",
+		"RUN --mount=type=secret,id=TOKEN,env=TOKEN <<'CMDSETGO'\nsource .env",
+	)
+}
+
+func TestRebasePath(t *testing.T) {
+	tests := map[string]string{
+		"ls /repo":              "ls /src",
+		"cp /repo/a /repo2/b":   "cp /src/a /repo2/b",
+		`cat "/repo/x" /repo.d`: `cat "/src/x" /repo.d`,
+	}
+	for in, want := range tests {
+		if got := rebasePath(in, "/repo", "/src"); got != want {
+			t.Errorf("rebasePath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
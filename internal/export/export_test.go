@@ -0,0 +1,57 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// testSelection builds a selection of steps recorded under root.
+func testSelection(root string, steps ...pick.Item) pick.Selection {
+	return pick.Selection{ID: "test", RepoRoot: root, Items: steps}
+}
+
+func cmdStep(cwd, cmd string) pick.Item {
+	return pick.Item{CmdEvent: events.CmdEvent{Type: events.TypeCmd, Cwd: cwd, Cmd: cmd}}
+}
+
+func noteStep(text string) pick.Item {
+	return pick.Item{CmdEvent: events.CmdEvent{Type: events.TypeNote, Cmd: text}}
+}
+
+func markerStep(text string) pick.Item {
+	return pick.Item{CmdEvent: events.CmdEvent{Type: events.TypeMarker, Cmd: text}}
+}
+
+// secretSelection has secrets in a prefix assignment, a flag and a note,
+// and a repeated variable name.
+func secretSelection() pick.Selection {
+	return testSelection("/repo",
+		cmdStep("/repo", "export GITHUB_TOKEN=ghp_one"),
+		cmdStep("/repo", `gh api --token ghp_one && GITHUB_TOKEN="ghp_two" gh pr list`),
+		noteStep("token is GITHUB_TOKEN=ghp_one"),
+	)
+}
+
+// exportString runs an exporter and returns its output.
+func exportString(t *testing.T, exporter ExportFunc, selection pick.Selection, opts Options) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := exporter(&out, selection, opts); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+// assertContains reports each of wants missing from got.
+func assertContains(t *testing.T, got string, wants ...string) {
+	t.Helper()
+	for _, want := range wants {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// ExternalPrefix is the executable name prefix of external exporters:
+// cmdsetgo-export-<name> on PATH provides the format <name>.
+const ExternalPrefix = "cmdsetgo-export-"
+
+// externalExporter runs an executable that reads the selection as JSON on
+// stdin and writes the export to stdout.
+type externalExporter struct {
+	path string
+}
+
+// externalInput is the JSON an external exporter reads: the prepared
+// selection and the variables its redacted commands require.
+type externalInput struct {
+	pick.Selection
+	// Required lists the variables replacing redacted secrets, in order of
+	// first use, as in the header of the bash export.
+	Required []string `json:"required,omitempty"`
+}
+
+// Export runs the executable with the selection already redacted and
+// parameterized, so external formats never see the recorded secrets.
+func (e externalExporter) Export(w io.Writer, selection pick.Selection, opts Options) error {
+	prepared, required := preparedSelection(selection, opts)
+	input, err := json.Marshal(externalInput{Selection: prepared, Required: required})
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(e.path)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(e.path), err)
+	}
+	return nil
+}
+
+// External returns the external format name, if its executable is on PATH.
+func External(name string) (Format, bool) {
+	if name == "" || strings.ContainsRune(name, filepath.Separator) {
+		return Format{}, false
+	}
+	path, err := exec.LookPath(ExternalPrefix + name)
+	if err != nil {
+		return Format{}, false
+	}
+	return externalFormat(name, path), true
+}
+
+// ExternalFormats returns the external exporters on PATH, sorted by name.
+// Executables shadowed by a built-in format or an earlier PATH entry are
+// left out.
+func ExternalFormats() []Format {
+	seen := make(map[string]bool)
+	var found []Format
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(dir, ExternalPrefix+"*"))
+		for _, path := range matches {
+			name := strings.TrimPrefix(filepath.Base(path), ExternalPrefix)
+			if _, builtin := lookupBuiltin(name); builtin || seen[name] {
+				continue
+			}
+			if info, err := os.Stat(path); err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+				continue
+			}
+			seen[name] = true
+			found = append(found, externalFormat(name, path))
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Name < found[j].Name })
+	return found
+}
+
+func externalFormat(name, path string) Format {
+	return Format{Name: name, Description: "External exporter", Path: path, Exporter: externalExporter{path: path}}
+}
+
+// preparedSelection returns a copy of the selection as the built-in formats
+// would write it, and the variables required by its commands: commands
+// redacted and with params substituted, directories with params
+// substituted, notes, markers and annotations redacted, and Params set to
+// every hoisted parameter.
+func preparedSelection(selection pick.Selection, opts Options) (pick.Selection, []string) {
+	selection = redactAnnotations(selection, opts)
+	cmds, required := redactCommands(selection, opts)
+	prepared := selection
+	prepared.Params = opts.Params
+	prepared.Items = make([]pick.Item, len(selection.Items))
+	for i, item := range selection.Items {
+		if item.IsCommand() {
+			item.Cmd = cmds[i]
+			item.Cwd = substitute(item.Cwd, opts.Params)
+		}
+		prepared.Items[i] = item
+	}
+	return prepared, required
+}
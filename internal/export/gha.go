@@ -0,0 +1,85 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// GitHubActionsExporter generates a GitHub Actions workflow with a single
+// manually triggered job and one named step per command. Directories become
+// working-directory paths relative to the checkout, and redacted secrets are
+// read from repository secrets of the same name.
+func GitHubActionsExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	steps, required := ciSteps(selection, opts)
+
+	writeCIHeader(w, selection, opts)
+	name := selection.Name
+	if name == "" {
+		name = "cmdsetgo runbook"
+	}
+	fmt.Fprintf(w, "name: %s\n\n", yamlString(ghaEscape(name)))
+	fmt.Fprintln(w, "on:")
+	fmt.Fprintln(w, "  workflow_dispatch:")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "defaults:")
+	fmt.Fprintln(w, "  run:")
+	fmt.Fprintln(w, "    shell: bash")
+	fmt.Fprintln(w)
+
+	if len(opts.Params) > 0 {
+		var params []pick.Param
+		for _, p := range opts.Params {
+			p.Value = ghaEscape(p.Value)
+			params = append(params, p)
+		}
+		fmt.Fprintln(w, "env:")
+		for _, p := range ciParams(selection, params, "${{ github.workspace }}") {
+			fmt.Fprintf(w, "  %s: %s", p.Name, yamlString(p.Value))
+			if p.Description != "" {
+				fmt.Fprintf(w, "  # %s", oneLine(p.Description))
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "jobs:")
+	fmt.Fprintf(w, "  %s:\n", ciJobName(selection))
+	fmt.Fprintln(w, "    runs-on: ubuntu-latest")
+	if len(required) > 0 {
+		fmt.Fprintln(w, "    # Secrets redacted from the recording; add them under Settings > Secrets.")
+		fmt.Fprintln(w, "    env:")
+		for _, v := range required {
+			fmt.Fprintf(w, "      %s: ${{ secrets.%s }}\n", v, ghaSecretName(v))
+		}
+	}
+	fmt.Fprintln(w, "    steps:")
+	fmt.Fprintln(w, "      - uses: actions/checkout@v4")
+	for _, step := range steps {
+		writeYAMLComments(w, step.comments, "      ")
+		fmt.Fprintf(w, "      - name: %s\n", yamlString(ghaEscape(step.name)))
+		if step.dir != "." {
+			fmt.Fprintf(w, "        working-directory: %s\n", yamlString(step.dir))
+		}
+		writeYAMLValue(w, "        run:", ghaEscape(step.cmd), "          ")
+	}
+	return nil
+}
+
+// ghaEscape keeps "${{" in recorded text from being evaluated as a workflow
+// expression.
+func ghaEscape(s string) string {
+	return strings.ReplaceAll(s, "${{", "${{ '${{' }}")
+}
+
+// ghaSecretName returns the repository secret holding variable v. Secret
+// names may not start with GITHUB_, except for the built-in GITHUB_TOKEN.
+func ghaSecretName(v string) string {
+	if v != "GITHUB_TOKEN" && strings.HasPrefix(v, "GITHUB_") {
+		return "GH_" + strings.TrimPrefix(v, "GITHUB_")
+	}
+	return v
+}
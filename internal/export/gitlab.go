@@ -0,0 +1,67 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// GitLabCIExporter generates a .gitlab-ci.yml with a single job. Each
+// command is a collapsible section of the job log named after the step,
+// run after a cd into its directory relative to the checkout. Redacted
+// secrets are read from CI/CD variables of the same name.
+func GitLabCIExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	steps, required := ciSteps(selection, opts)
+
+	writeCIHeader(w, selection, opts)
+	if len(opts.Params) > 0 {
+		var params []pick.Param
+		for _, p := range opts.Params {
+			// GitLab expands variables in variable values.
+			p.Value = strings.ReplaceAll(p.Value, "$", "$$")
+			params = append(params, p)
+		}
+		fmt.Fprintln(w, "variables:")
+		for _, p := range ciParams(selection, params, "$CI_PROJECT_DIR") {
+			fmt.Fprintf(w, "  %s: %s", p.Name, yamlString(p.Value))
+			if p.Description != "" {
+				fmt.Fprintf(w, "  # %s", oneLine(p.Description))
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%s:\n", ciJobName(selection))
+	if len(required) > 0 {
+		fmt.Fprintf(w, "  # Secrets redacted from the recording; define them as masked CI/CD variables: %s\n", strings.Join(required, ", "))
+	}
+	fmt.Fprintln(w, "  script:")
+	dir := ""
+	for i, step := range steps {
+		writeYAMLComments(w, step.comments, "    ")
+		section := fmt.Sprintf("step_%d", i+1)
+		fmt.Fprintf(w, "    - %s\n", yamlString(fmt.Sprintf(`echo -e "\e[0Ksection_start:$(date +%%s):%s\r\e[0K%s"`, section, gitlabEcho(step.name))))
+		if step.dir != dir {
+			target := "$CI_PROJECT_DIR"
+			if step.dir != "." {
+				target += "/" + step.dir
+			}
+			if strings.HasPrefix(step.dir, "/") {
+				target = step.dir
+			}
+			fmt.Fprintf(w, "    - %s\n", yamlString(fmt.Sprintf(`cd "%s"`, target)))
+			dir = step.dir
+		}
+		writeYAMLValue(w, "    -", step.cmd, "      ")
+		fmt.Fprintf(w, "    - %s\n", yamlString(fmt.Sprintf(`echo -e "\e[0Ksection_end:$(date +%%s):%s\r\e[0K"`, section)))
+	}
+	return nil
+}
+
+// gitlabEcho escapes text for a double-quoted echo -e argument.
+func gitlabEcho(s string) string {
+	return strings.NewReplacer(`\`, `\\\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s)
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+//go:embed html/runbook.html
+var htmlRunbook string
+
+// HTMLExporter generates a self-contained HTML runbook: a table of contents
+// with one entry per directory group, and a collapsible block per step with
+// a copy button and exit code and duration badges. Commands go through the
+// same redaction as the other formats, and everything is HTML-escaped.
+func HTMLExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	funcs := template.FuncMap{
+		"groups":       groupSteps,
+		"substitute":   func(s string) string { return substitute(s, opts.Params) },
+		"shelldefault": shellDefault,
+		"firstline":    func(s string) string { return firstLineOf(s, 80) },
+		"duration": func(ms int64) string {
+			return (time.Duration(ms) * time.Millisecond).Round(time.Millisecond).String()
+		},
+		// commands counts the command steps of a group.
+		"commands": func(steps []TemplateStep) int {
+			n := 0
+			for _, step := range steps {
+				if step.IsCommand() {
+					n++
+				}
+			}
+			return n
+		},
+		// firstcommand returns the number of a group's first command step.
+		"firstcommand": func(steps []TemplateStep) int {
+			for _, step := range steps {
+				if step.IsCommand() {
+					return step.Number
+				}
+			}
+			return 0
+		},
+	}
+	tmpl, err := template.New("runbook").Funcs(funcs).Parse(htmlRunbook)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, newTemplateData(selection, opts))
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLExporter(t *testing.T) {
+	selection := testSelection("/repo",
+		cmdStep("/repo", "export GITHUB_TOKEN=ghp_one"),
+		noteStep("<b>careful</b> with GITHUB_TOKEN=ghp_one"),
+		cmdStep("/repo/web", `echo "<script>alert(1)</script>" && false`),
+	)
+	selection.Name = "deploy <prod>"
+	selection.Description = "Deploy with GITHUB_TOKEN=ghp_one"
+	selection.Items[0].DurationMs = 1500
+	selection.Items[2].Exit = 1
+	selection.Items[2].Section = "Web & UI"
+	selection.Items[2].Title = "Test with GITHUB_TOKEN=ghp_one"
+	selection.Items[2].Note = "<i>uses</i> GITHUB_TOKEN=ghp_one"
+
+	got := exportString(t, HTMLExporter, selection, Options{})
+	assertContains(t, got,
+		"<title>deploy &lt;prod&gt;</title>",
+		`<a href="#group-0"><code>/repo</code></a> <span class="count">1 step</span>`,
+		`<a href="#group-1">Web &amp; UI · <code>/repo/web</code></a>`,
+		"<li><code>GITHUB_TOKEN</code></li>",
+		`<span class="badge ok">exit 0</span> <span class="badge">1.5s</span>`,
+		`<span class="badge fail">exit 1</span>`,
+		"&lt;b&gt;careful&lt;/b&gt; with GITHUB_TOKEN=***REDACTED***",
+		"<code>echo &#34;&lt;script&gt;alert(1)&lt;/script&gt;&#34; &amp;&amp; false</code>",
+		"<p>Deploy with GITHUB_TOKEN=***REDACTED***</p>",
+		`<span class="title">Test with GITHUB_TOKEN=***REDACTED***</span>`,
+		`<p class="step-note">&lt;i&gt;uses&lt;/i&gt; GITHUB_TOKEN=***REDACTED***</p>`,
+		`<button type="button" class="copy">Copy</button>`,
+	)
+	if strings.Contains(got, "ghp_one") || strings.Contains(got, "<script>alert") {
+		t.Errorf("HTML leaks a secret or unescaped markup:\n%s", got)
+	}
+	// Self-contained: no stylesheets, scripts or images from elsewhere.
+	for _, ref := range []string{"src=", "<link", "http://", "https://"} {
+		if strings.Contains(got, ref) {
+			t.Errorf("HTML references external resources (%s)", ref)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// JustExporter generates a justfile with one recipe per step (or per
+// directory with Options.GroupByCwd) and a default "all" recipe running
+// them in order. Recipes are bash scripts, so the cd at the top of a recipe
+// applies to all of its commands.
+func JustExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	tasks, required := buildTasks(selection, opts)
+
+	writeTaskHeader(w, selection, opts, required)
+	fmt.Fprintln(w, `set shell := ["bash", "-euo", "pipefail", "-c"]`)
+	fmt.Fprintln(w)
+
+	if len(opts.Params) > 0 {
+		fmt.Fprintln(w, "# Parameters (override from the environment)")
+		for _, p := range opts.Params {
+			writeCommentLines(w, p.Description)
+			fmt.Fprintf(w, "export %s := env_var_or_default(%q, %s)\n", p.Name, p.Name, justString(p.Value))
+		}
+		fmt.Fprintln(w)
+	}
+
+	names := make([]string, len(tasks))
+	for i, t := range tasks {
+		names[i] = t.name
+	}
+	fmt.Fprintln(w, "# Run every step in order")
+	fmt.Fprintf(w, "%s: %s\n", aggregateTask, strings.Join(names, " "))
+
+	for _, t := range tasks {
+		fmt.Fprintln(w)
+		writeCommentLines(w, strings.Join(t.doc, "\n"))
+		fmt.Fprintf(w, "%s:\n", t.name)
+		lines := append([]string{"#!/usr/bin/env bash", "set -euo pipefail", fmt.Sprintf("cd \"%s\"", t.cwd)}, t.body...)
+		writeRecipe(w, lines, "    ", justEscape)
+	}
+	return nil
+}
+
+// justEscape escapes just's {{ interpolation.
+func justEscape(s string) string {
+	return strings.ReplaceAll(s, "{{", "{{{{")
+}
+
+// justString quotes s as a just string literal.
+func justString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s) + `"`
+}
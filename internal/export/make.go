@@ -0,0 +1,87 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// MakeExporter generates a GNU Makefile with one phony target per step (or
+// per directory with Options.GroupByCwd) and an "all" target running them
+// in order. Recipes run in a single strict-mode bash shell each, so the cd
+// at the top of a recipe applies to all of its commands.
+func MakeExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	tasks, required := buildTasks(selection, opts)
+
+	writeTaskHeader(w, selection, opts, required)
+	fmt.Fprintln(w, "SHELL := bash")
+	fmt.Fprintln(w, ".SHELLFLAGS := -euo pipefail -c")
+	fmt.Fprintln(w, ".ONESHELL:")
+	fmt.Fprintln(w, ".NOTPARALLEL:")
+	names := make([]string, len(tasks))
+	for i, t := range tasks {
+		names[i] = t.name
+	}
+	fmt.Fprintf(w, ".PHONY: %s\n\n", strings.Join(append([]string{aggregateTask}, names...), " "))
+
+	if len(opts.Params) > 0 {
+		fmt.Fprintln(w, "# Parameters (override with make NAME=value or from the environment)")
+		for _, p := range opts.Params {
+			writeCommentLines(w, p.Description)
+			fmt.Fprintf(w, "export %s ?= %s\n", p.Name, makeValue(p.Value))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w, "# Run every step in order")
+	fmt.Fprintf(w, "%s: %s\n", aggregateTask, strings.Join(names, " "))
+
+	for _, t := range tasks {
+		fmt.Fprintln(w)
+		writeCommentLines(w, strings.Join(t.doc, "\n"))
+		fmt.Fprintf(w, "%s:\n", t.name)
+		writeRecipe(w, append([]string{fmt.Sprintf("cd \"%s\"", t.cwd)}, t.body...), "\t", makeEscape)
+	}
+	return nil
+}
+
+// makeEscape doubles dollar signs so make passes them to the shell.
+func makeEscape(s string) string {
+	return strings.ReplaceAll(s, "$", "$$")
+}
+
+// makeValue escapes a variable value for a makefile assignment.
+func makeValue(s string) string {
+	return strings.ReplaceAll(makeEscape(s), "#", `\#`)
+}
+
+// writeTaskHeader writes the comment block at the top of a makefile or
+// justfile.
+func writeTaskHeader(w io.Writer, selection pick.Selection, opts Options, required []string) {
+	fmt.Fprintf(w, "# Generated by cmdsetgo at %s\n", time.Now().Format(time.RFC1123))
+	if selection.Name != "" {
+		fmt.Fprintf(w, "# Selection: %s\n", selection.Name)
+	}
+	writeCommentLines(w, opts.redactor().Redact(selection.Description))
+	fmt.Fprintf(w, "# Scope: %s\n", selection.Scope)
+	if selection.RepoRoot != "" {
+		fmt.Fprintf(w, "# Repo: %s\n", selection.RepoRoot)
+	}
+	if len(required) > 0 {
+		fmt.Fprintf(w, "# Required environment variables (redacted from the recording): %s\n", strings.Join(required, ", "))
+	}
+	fmt.Fprintln(w)
+}
+
+// writeRecipe writes the lines of a recipe, indenting every line of
+// multi-line commands so they stay part of it.
+func writeRecipe(w io.Writer, lines []string, indent string, escape func(string) string) {
+	for _, line := range lines {
+		for _, l := range strings.Split(escape(line), "\n") {
+			fmt.Fprintf(w, "%s%s\n", indent, l)
+		}
+	}
+}
@@ -1,38 +1,26 @@
 package export
 
 import (
-	"fmt"
 	"io"
-	"time"
+	"strings"
 
 	"github.com/drakeafk/cmdsetgo/internal/pick"
-	"github.com/drakeafk/cmdsetgo/internal/redact"
 )
 
-// MarkdownExporter generates a markdown runbook.
-func MarkdownExporter(w io.Writer, selection pick.Selection, redactRegex []string) error {
-	fmt.Fprintln(w, "# cmdsetgo runbook")
-	fmt.Fprintf(w, "\nGenerated at %s  \n", time.Now().Format(time.RFC1123))
-	fmt.Fprintf(w, "Scope: `%s`  \n", selection.Scope)
-	if selection.RepoRoot != "" {
-		fmt.Fprintf(w, "Repo Root: `%s`  \n", selection.RepoRoot)
-	}
-	fmt.Fprintln(w)
-
-	currentCwd := ""
-	for _, ev := range selection.Items {
-		if ev.Cwd != currentCwd {
-			fmt.Fprintf(w, "## In `%s`\n\n", ev.Cwd)
-			currentCwd = ev.Cwd
-		}
+// MarkdownExporter generates a markdown runbook from the built-in
+// "markdown" template.
+func MarkdownExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	return renderShipped(w, "markdown", selection, opts)
+}
 
-		cmdRedacted := redact.Redact(ev.Cmd, redactRegex)
-		fmt.Fprintln(w, "```bash")
-		fmt.Fprintf(w, "# %s\n", ev.Ts.Format(time.RFC3339))
-		fmt.Fprintln(w, cmdRedacted)
-		fmt.Fprintln(w, "```")
-		fmt.Fprintln(w)
-	}
+// RunmeExporter generates the markdown runbook with each code block named
+// and given its directory in Runme's fence attributes, so the blocks can be
+// run on their own by tools that execute markdown.
+func RunmeExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	return renderShipped(w, "runme", selection, opts)
+}
 
-	return nil
+// tableCell escapes text for use inside a markdown table cell.
+func tableCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
 }
@@ -0,0 +1,151 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// notebook is a Jupyter notebook in nbformat 4.
+type notebook struct {
+	Cells         []notebookCell `json:"cells"`
+	Metadata      map[string]any `json:"metadata"`
+	NBFormat      int            `json:"nbformat"`
+	NBFormatMinor int            `json:"nbformat_minor"`
+}
+
+type notebookCell struct {
+	ID       string         `json:"id"`
+	CellType string         `json:"cell_type"`
+	Metadata map[string]any `json:"metadata"`
+	Source   []string       `json:"source"`
+	// Code cells must have execution_count (null until run) and outputs;
+	// markdown cells must not.
+	ExecutionCount json.RawMessage `json:"execution_count,omitempty"`
+	Outputs        *[]struct{}     `json:"outputs,omitempty"`
+}
+
+// NotebookExporter generates a Jupyter notebook for the bash kernel: a code
+// cell per step, with markdown cells for the header, sections, notes and
+// every change of directory. The first step in a directory starts with its
+// cd, since the kernel keeps one shell across cells.
+func NotebookExporter(w io.Writer, selection pick.Selection, opts Options) error {
+	data := newTemplateData(selection, opts)
+	nb := notebook{
+		Metadata: map[string]any{
+			"kernelspec":    map[string]string{"name": "bash", "display_name": "Bash", "language": "bash"},
+			"language_info": map[string]string{"name": "bash"},
+		},
+		NBFormat:      4,
+		NBFormatMinor: 5,
+	}
+	markdown := func(text string) {
+		nb.Cells = append(nb.Cells, notebookCell{CellType: "markdown", Source: notebookSource(text)})
+	}
+	code := func(text string, metadata map[string]any) {
+		nb.Cells = append(nb.Cells, notebookCell{
+			CellType:       "code",
+			Metadata:       metadata,
+			Source:         notebookSource(text),
+			ExecutionCount: json.RawMessage("null"),
+			Outputs:        &[]struct{}{},
+		})
+	}
+
+	title := selection.Name
+	if title == "" {
+		title = "cmdsetgo runbook"
+	}
+	var header strings.Builder
+	fmt.Fprintf(&header, "# %s\n\n", title)
+	if data.Selection.Description != "" {
+		fmt.Fprintf(&header, "%s\n\n", data.Selection.Description)
+	}
+	fmt.Fprintf(&header, "Generated at %s  \nScope: `%s`", data.Now.Format(time.RFC1123), selection.Scope)
+	if selection.RepoRoot != "" {
+		fmt.Fprintf(&header, "  \nRepo Root: `%s`", selection.RepoRoot)
+	}
+	markdown(header.String())
+
+	if len(data.Required) > 0 {
+		markdown("## Prerequisites\n\nSecrets were redacted from the recorded commands. Export these environment variables before running the steps.")
+		var checks []string
+		for _, name := range data.Required {
+			checks = append(checks, fmt.Sprintf(": \"${%s:?must be set}\"", name))
+		}
+		code(strings.Join(checks, "\n"), nil)
+	}
+	if len(data.Params) > 0 {
+		markdown("## Parameters\n\nOverride the defaults from the environment before starting the notebook, or edit them here.")
+		var lines []string
+		for _, p := range data.Params {
+			line := fmt.Sprintf("%s=\"${%s:-%s}\"", p.Name, p.Name, shellDefault(p.Value))
+			if p.Description != "" {
+				line += "  # " + oneLine(p.Description)
+			}
+			lines = append(lines, line)
+		}
+		code(strings.Join(lines, "\n"), nil)
+	}
+
+	cwd := ""
+	for _, step := range data.Steps {
+		if step.Section != "" {
+			markdown("## " + oneLine(step.Section))
+			cwd = ""
+		}
+		switch step.Type {
+		case events.TypeNote:
+			markdown(step.Command)
+			continue
+		case events.TypeMarker:
+			markdown("**" + step.Command + "**")
+			continue
+		}
+
+		dir := substitute(step.Cwd, opts.Params)
+		source := step.Command
+		if step.Cwd != cwd {
+			markdown(fmt.Sprintf("### In `%s`", dir))
+			source = fmt.Sprintf("cd \"%s\"\n%s", dir, source)
+			cwd = step.Cwd
+		}
+		var doc []string
+		if step.Title != "" {
+			doc = append(doc, "#### "+oneLine(step.Title))
+		}
+		if step.Note != "" {
+			doc = append(doc, step.Note)
+		}
+		if len(doc) > 0 {
+			markdown(strings.Join(doc, "\n\n"))
+		}
+		code(source, map[string]any{"cmdsetgo": map[string]any{"step": step.Number, "name": step.Name, "cwd": dir}})
+	}
+
+	for i := range nb.Cells {
+		nb.Cells[i].ID = fmt.Sprintf("cell-%d", i+1)
+		if nb.Cells[i].Metadata == nil {
+			nb.Cells[i].Metadata = map[string]any{}
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(nb)
+}
+
+// notebookSource splits text into lines that keep their newlines, as
+// notebooks store cell sources.
+func notebookSource(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
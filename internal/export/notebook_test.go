@@ -0,0 +1,96 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+func documentSelection() pick.Selection {
+	selection := testSelection("/repo",
+		cmdStep("/repo", "export GITHUB_TOKEN=ghp_one"),
+		noteStep("then build"),
+		cmdStep("/repo/web", "npm ci"),
+		cmdStep("/repo/web", "npm test"),
+	)
+	selection.Name = "web"
+	selection.Items[3].Title = "Run the tests"
+	return selection
+}
+
+func TestNotebookExporter(t *testing.T) {
+	out := exportString(t, NotebookExporter, documentSelection(), Options{})
+	var nb struct {
+		Cells []struct {
+			ID             string          `json:"id"`
+			CellType       string          `json:"cell_type"`
+			Source         []string        `json:"source"`
+			ExecutionCount json.RawMessage `json:"execution_count"`
+			Outputs        []any           `json:"outputs"`
+			Metadata       struct {
+				Cmdsetgo struct {
+					Name string `json:"name"`
+					Cwd  string `json:"cwd"`
+				} `json:"cmdsetgo"`
+			} `json:"metadata"`
+		} `json:"cells"`
+		Metadata struct {
+			Kernelspec struct {
+				Name string `json:"name"`
+			} `json:"kernelspec"`
+		} `json:"metadata"`
+		NBFormat int `json:"nbformat"`
+	}
+	if err := json.Unmarshal([]byte(out), &nb); err != nil {
+		t.Fatal(err)
+	}
+	if nb.NBFormat != 4 || nb.Metadata.Kernelspec.Name != "bash" {
+		t.Errorf("nbformat %d, kernel %q", nb.NBFormat, nb.Metadata.Kernelspec.Name)
+	}
+
+	var got []string
+	for _, c := range nb.Cells {
+		source := strings.Join(c.Source, "")
+		if c.CellType == "code" {
+			if string(c.ExecutionCount) != "null" || c.Outputs == nil {
+				t.Errorf("code cell %s: execution_count %s, outputs %v", c.ID, c.ExecutionCount, c.Outputs)
+			}
+			if c.Metadata.Cmdsetgo.Name != "" {
+				source = c.Metadata.Cmdsetgo.Name + "@" + c.Metadata.Cmdsetgo.Cwd + ": " + source
+			}
+		}
+		got = append(got, c.CellType+" "+source)
+	}
+	want := []string{
+		"markdown # web\n\nGenerated at ",
+		"markdown ## Prerequisites",
+		`code : "${GITHUB_TOKEN:?must be set}"`,
+		"markdown ### In `/repo`",
+		"code export@/repo: cd \"/repo\"\nexport GITHUB_TOKEN=\"${GITHUB_TOKEN:?must be set}\"",
+		"markdown then build",
+		"markdown ### In `/repo/web`",
+		"code npm-ci@/repo/web: cd \"/repo/web\"\nnpm ci",
+		"markdown #### Run the tests",
+		"code run-the-tests@/repo/web: npm test",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("cells:\n%s", strings.Join(got, "\n---\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("cell %d = %q, want prefix %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRunmeExporter(t *testing.T) {
+	assertContains(t, exportString(t, RunmeExporter, documentSelection(), Options{}),
+		"---\nshell: bash\n---\n",
+		"```sh {\"name\":\"prerequisites\"}\n: \"${GITHUB_TOKEN:?must be set}\"\n```\n",
+		"```sh {\"name\":\"export\",\"cwd\":\".\"}\nexport GITHUB_TOKEN=\"${GITHUB_TOKEN:?must be set}\"\n```\n",
+		"then build\n",
+		"### Run the tests\n\n```sh {\"name\":\"run-the-tests\",\"cwd\":\"web\"}\nnpm test\n```\n",
+	)
+}
@@ -0,0 +1,261 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/redact"
+)
+
+// Options controls what exporters do with a selection's commands.
+type Options struct {
+	// Redactor finds secrets to remove. Nil means the default rules.
+	Redactor *redact.Redactor
+	// Params are hoisted into variables; see pick.Param.
+	Params []pick.Param
+	// GroupByCwd makes one make target or just recipe of consecutive steps
+	// run in the same directory instead of one per step.
+	GroupByCwd bool
+	// MergeRuns joins consecutive steps in the same directory into one
+	// Dockerfile RUN instruction (one image layer).
+	MergeRuns bool
+	// Warnings receives notes about steps an exporter could not translate
+	// faithfully, one per line. Nil discards them.
+	Warnings io.Writer
+}
+
+func (o Options) redactor() *redact.Redactor {
+	if o.Redactor == nil {
+		return redact.Default()
+	}
+	return o.Redactor
+}
+
+func (o Options) warnf(format string, args ...any) {
+	if o.Warnings != nil {
+		fmt.Fprintf(o.Warnings, "warning: "+format+"\n", args...)
+	}
+}
+
+var (
+	homeRegex    = regexp.MustCompile(`^(/home/[^/]+|/Users/[^/]+|/root)(/|$)`)
+	versionRegex = regexp.MustCompile(`(?:([A-Za-z][A-Za-z0-9_]*?)[@:=]?)?v?(\d+\.\d+(?:\.\d+)?)`)
+)
+
+// DetectParams finds literals repeated across the selection's steps that are
+// worth turning into variables: the repo root, the home directory, the user
+// name and version strings. Their names differ from the environment
+// variables HOME and USER, which the CI and Dockerfile exports would
+// otherwise override with the recorded values. Each must occur at least twice once the earlier
+// candidates have been substituted, so a home directory that only appears
+// as part of the repo root is not reported. Names or values already in
+// opts.Params are skipped. Commands are redacted with opts.Redactor first,
+// so no part of a secret becomes a parameter default.
+func DetectParams(selection pick.Selection, opts Options) []pick.Param {
+	var texts []string
+	for _, item := range selection.Items {
+		if item.IsCommand() {
+			texts = append(texts, item.Cwd, opts.redactor().Redact(item.Cmd))
+		}
+	}
+
+	taken := make(map[string]bool)
+	for _, p := range opts.Params {
+		taken[p.Name] = true
+		taken["="+p.Value] = true
+		texts = substituteAll(texts, []pick.Param{p})
+	}
+
+	var found []pick.Param
+	try := func(name, value, description string) {
+		if value == "" || taken["="+value] {
+			return
+		}
+		base := name
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		p := pick.Param{Name: name, Value: value, Description: description}
+		if countParam(texts, p) < 2 {
+			return
+		}
+		taken[name] = true
+		taken["="+value] = true
+		found = append(found, p)
+		texts = substituteAll(texts, []pick.Param{p})
+	}
+
+	try("REPO_ROOT", selection.RepoRoot, "Repository checkout")
+
+	home := ""
+	for _, t := range append([]string{selection.RepoRoot}, texts...) {
+		if m := homeRegex.FindStringSubmatch(t); m != nil {
+			home = m[1]
+			break
+		}
+	}
+	try("HOME_DIR", home, "Home directory")
+
+	if user := singleUser(selection); user != "" {
+		try("USER_NAME", user, "User name")
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range texts {
+		for _, m := range versionRegex.FindAllStringSubmatchIndex(t, -1) {
+			value := t[m[4]:m[5]]
+			if seen[value] || !atBoundary(t, m[4], m[5], value) {
+				continue
+			}
+			seen[value] = true
+			name, description := "VERSION", "Version"
+			if m[2] >= 0 {
+				if prefix := t[m[2]:m[3]]; !strings.EqualFold(prefix, "v") && !strings.HasSuffix(strings.ToUpper(prefix), "VERSION") {
+					name, description = strings.ToUpper(prefix)+"_VERSION", prefix+" version"
+				}
+			}
+			try(name, value, description)
+		}
+	}
+
+	return found
+}
+
+// singleUser returns the user who recorded every command, or "" if the
+// selection mixes users.
+func singleUser(selection pick.Selection) string {
+	user := ""
+	for _, item := range selection.Items {
+		if !item.IsCommand() || item.User == "" {
+			continue
+		}
+		if user != "" && item.User != user {
+			return ""
+		}
+		user = item.User
+	}
+	return user
+}
+
+// substitute replaces the values of params in s with ${NAME} references,
+// longest value first. Single-quoted text is left alone because the shell
+// would not expand the variable there.
+func substitute(s string, params []pick.Param) string {
+	out, _ := substituteCount(s, params)
+	return out
+}
+
+func substituteAll(texts []string, params []pick.Param) []string {
+	out := make([]string, len(texts))
+	for i, t := range texts {
+		out[i] = substitute(t, params)
+	}
+	return out
+}
+
+func countParam(texts []string, p pick.Param) int {
+	total := 0
+	for _, t := range texts {
+		_, n := substituteCount(t, []pick.Param{p})
+		total += n
+	}
+	return total
+}
+
+func substituteCount(s string, params []pick.Param) (string, int) {
+	if len(params) == 0 {
+		return s, 0
+	}
+	sorted := append([]pick.Param(nil), params...)
+	sort.SliceStable(sorted, func(i, j int) bool { return len(sorted[i].Value) > len(sorted[j].Value) })
+
+	var b strings.Builder
+	count := 0
+	inSingle, inDouble := false, false
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case inSingle:
+			if c == '\'' {
+				inSingle = false
+			}
+		case c == '\\' && i+1 < len(s):
+			b.WriteString(s[i : i+2])
+			i += 2
+			continue
+		case c == '\'' && !inDouble:
+			inSingle = true
+		case c == '"':
+			inDouble = !inDouble
+		default:
+			if p, ok := paramAt(s, i, sorted); ok {
+				b.WriteString("${" + p.Name + "}")
+				i += len(p.Value)
+				count++
+				continue
+			}
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String(), count
+}
+
+func paramAt(s string, i int, params []pick.Param) (pick.Param, bool) {
+	for _, p := range params {
+		if p.Value != "" && strings.HasPrefix(s[i:], p.Value) && atBoundary(s, i, i+len(p.Value), p.Value) {
+			return p, true
+		}
+	}
+	return pick.Param{}, false
+}
+
+// atBoundary reports whether value at s[i:j] is a whole token rather than
+// part of a longer name, path segment or number. Version numbers may follow
+// letters ("go1.22") but not other digits.
+func atBoundary(s string, i, j int, value string) bool {
+	first, last := value[0], value[len(value)-1]
+	if i > 0 {
+		prev := s[i-1]
+		switch {
+		case isDigit(first):
+			if isDigit(prev) || prev == '.' {
+				return false
+			}
+		case isWordByte(first):
+			if isWordByte(prev) || prev == '.' || prev == '-' {
+				return false
+			}
+		}
+	}
+	if j < len(s) {
+		next := s[j]
+		switch {
+		case isDigit(last):
+			if isDigit(next) || (next == '.' && j+1 < len(s) && isDigit(s[j+1])) {
+				return false
+			}
+		case isWordByte(last):
+			if isWordByte(next) || next == '.' || next == '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isDigit(b byte) bool { return b >= '0' && b <= '9' }
+
+func isWordByte(b byte) bool {
+	return isDigit(b) || b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// shellDefault quotes a parameter default for use inside "${NAME:-...}".
+func shellDefault(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`", "}", `\}`)
+	return r.Replace(value)
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+func paramSelection() pick.Selection {
+	selection := testSelection("/home/alice/src/app",
+		cmdStep("/home/alice/src/app", "nvm use node@18.19.0"),
+		cmdStep("/home/alice/src/app/web", "cp ~/.npmrc /home/alice/.npmrc.bak && ls /home/alice/.config"),
+		cmdStep("/home/alice/src/app", "docker build -t app:18.19.0 --build-arg USER=alice --build-arg GROUP=alice ."),
+		cmdStep("/tmp", "echo 'alice at /home/alice/src/app'"),
+	)
+	for i := range selection.Items {
+		selection.Items[i].User = "alice"
+	}
+	return selection
+}
+
+func TestDetectParams(t *testing.T) {
+	got := DetectParams(paramSelection(), Options{})
+	var names, values []string
+	for _, p := range got {
+		names = append(names, p.Name)
+		values = append(values, p.Value)
+	}
+	wantNames := []string{"REPO_ROOT", "HOME_DIR", "USER_NAME", "NODE_VERSION"}
+	wantValues := []string{"/home/alice/src/app", "/home/alice", "alice", "18.19.0"}
+	if !reflect.DeepEqual(names, wantNames) || !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("DetectParams = %v %v, want %v %v", names, values, wantNames, wantValues)
+	}
+}
+
+func TestDetectParamsSkipsDeclared(t *testing.T) {
+	declared := []pick.Param{{Name: "APP", Value: "/home/alice/src/app"}, {Name: "USER_NAME", Value: "bob"}}
+	for _, p := range DetectParams(paramSelection(), Options{Params: declared}) {
+		if p.Name == "REPO_ROOT" || p.Name == "USER_NAME" {
+			t.Errorf("detected %s despite declared params", p.Name)
+		}
+		if p.Name == "USER_NAME_2" && p.Value != "alice" {
+			t.Errorf("USER_NAME_2 = %q", p.Value)
+		}
+	}
+}
+
+func TestDetectedParamsKeepTargetEnvironment(t *testing.T) {
+	sel := paramSelection()
+	opts := Options{Params: DetectParams(sel, Options{})}
+	tests := map[string]struct {
+		exporter ExportFunc
+		wants    []string
+	}{
+		"gha":        {GitHubActionsExporter, []string{`HOME_DIR: "/home/alice"`, `USER_NAME: "alice"`}},
+		"gitlab-ci":  {GitLabCIExporter, []string{`HOME_DIR: "/home/alice"`, `USER_NAME: "alice"`}},
+		"dockerfile": {DockerfileExporter, []string{`ARG HOME_DIR="/home/alice"`, `ARG USER_NAME="alice"`}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := exportString(t, tt.exporter, sel, opts)
+			assertContains(t, out, tt.wants...)
+			for _, env := range []string{"HOME", "USER"} {
+				if strings.Contains(out, " "+env+": ") || strings.Contains(out, "ARG "+env+"=") {
+					t.Errorf("export sets %s:\n%s", env, out)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectParamsIgnoresSecrets(t *testing.T) {
+	selection := testSelection("",
+		cmdStep("/srv", "mysql --password=pw2024.11.7 -e 'select 1'"),
+		cmdStep("/srv", "mysql --password=pw2024.11.7 -e 'select 2'"),
+	)
+	params := DetectParams(selection, Options{})
+	for _, p := range params {
+		if strings.Contains(p.Value, "2024") {
+			t.Errorf("detected %s=%q from a redacted password", p.Name, p.Value)
+		}
+	}
+	if script := exportString(t, BashExporter, selection, Options{Params: params}); strings.Contains(script, "2024") {
+		t.Errorf("script leaks part of the password:\n%s", script)
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	params := []pick.Param{
+		{Name: "HOME", Value: "/home/alice"},
+		{Name: "REPO_ROOT", Value: "/home/alice/src/app"},
+		{Name: "GO_VERSION", Value: "1.22"},
+	}
+	tests := []struct{ in, want string }{
+		{"cd /home/alice/src/app/web", "cd ${REPO_ROOT}/web"},
+		{"ls /home/alice /home/alice2", "ls ${HOME} /home/alice2"},
+		{`echo "/home/alice" '/home/alice'`, `echo "${HOME}" '/home/alice'`},
+		{"go install golang.org/dl/go1.22@latest 1.22.1 11.22", "go install golang.org/dl/go${GO_VERSION}@latest 1.22.1 11.22"},
+		{`echo \'/home/alice`, `echo \'${HOME}`},
+	}
+	for _, tt := range tests {
+		if got := substitute(tt.in, params); got != tt.want {
+			t.Errorf("substitute(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBashExporterParams(t *testing.T) {
+	sel := paramSelection()
+	params := []pick.Param{{Name: "REPO_ROOT", Value: sel.RepoRoot, Description: "Checkout"}, {Name: "TAG", Value: `a"$b`}}
+
+	assertContains(t, exportString(t, BashExporter, sel, Options{Params: params}),
+		`REPO_ROOT="${REPO_ROOT:-/home/alice/src/app}"  # Checkout`,
+		`TAG="${TAG:-a\"\$b}"`,
+		`cd "${REPO_ROOT}/web"`,
+	)
+}
+
+func TestMarkdownExporterParamsTable(t *testing.T) {
+	params := []pick.Param{{Name: "REPO_ROOT", Value: "/srv/a|b", Description: "Checkout"}}
+
+	assertContains(t, exportString(t, MarkdownExporter, paramSelection(), Options{Params: params}),
+		"| `REPO_ROOT` | `/srv/a\\|b` | Checkout |",
+	)
+}
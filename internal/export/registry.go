@@ -0,0 +1,101 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// Exporter writes a selection in one output format.
+type Exporter interface {
+	Export(w io.Writer, selection pick.Selection, opts Options) error
+}
+
+// ExportFunc adapts a function to the Exporter interface.
+type ExportFunc func(w io.Writer, selection pick.Selection, opts Options) error
+
+// Export calls f.
+func (f ExportFunc) Export(w io.Writer, selection pick.Selection, opts Options) error {
+	return f(w, selection, opts)
+}
+
+// Format is a named output format.
+type Format struct {
+	Name        string
+	Aliases     []string
+	Description string
+	// Ext is the file name suffix that selects the format when it ends the
+	// output path, e.g. ".sh" or "Makefile". Empty means never inferred.
+	Ext string
+	// Path is the executable of an external format; see External.
+	Path     string
+	Exporter Exporter
+}
+
+var formats = []Format{
+	{Name: "bash", Ext: ".sh", Description: "Strict-mode bash script", Exporter: ExportFunc(BashExporter)},
+	{Name: "md", Aliases: []string{"markdown"}, Ext: ".md", Description: "Markdown runbook", Exporter: ExportFunc(MarkdownExporter)},
+	{Name: "runme", Description: "Markdown runbook whose code blocks carry a name and directory for Runme", Exporter: ExportFunc(RunmeExporter)},
+	{Name: "ipynb", Aliases: []string{"notebook"}, Ext: ".ipynb", Description: "Jupyter notebook, a bash-kernel cell per step", Exporter: ExportFunc(NotebookExporter)},
+	{Name: "cast", Aliases: []string{"asciicast"}, Ext: ".cast", Description: "asciinema recording typing each command with the recorded pauses", Exporter: ExportFunc(CastExporter)},
+	{Name: "html", Ext: ".html", Description: "Self-contained HTML runbook with copy buttons and exit code badges", Exporter: ExportFunc(HTMLExporter)},
+	{Name: "make", Ext: "makefile", Description: "Makefile with a target per step (or per directory with --targets cwd) and \"all\"", Exporter: ExportFunc(MakeExporter)},
+	{Name: "just", Ext: "justfile", Description: "justfile with a recipe per step (or per directory with --targets cwd) and \"all\"", Exporter: ExportFunc(JustExporter)},
+	{Name: "gha", Ext: ".yml", Description: "GitHub Actions workflow, a step per command, secrets from ${{ secrets.NAME }}", Exporter: ExportFunc(GitHubActionsExporter)},
+	{Name: "gitlab-ci", Ext: ".gitlab-ci.yml", Description: "GitLab CI pipeline, a section per command, secrets from CI/CD variables", Exporter: ExportFunc(GitLabCIExporter)},
+	{Name: "dockerfile", Ext: "dockerfile", Description: "Dockerfile replaying the steps as RUN (one per directory with --merge-runs)", Exporter: ExportFunc(DockerfileExporter)},
+}
+
+// Register adds a format. Its name and aliases must not be taken.
+func Register(f Format) error {
+	for _, name := range append([]string{f.Name}, f.Aliases...) {
+		if _, ok := lookupBuiltin(name); ok {
+			return fmt.Errorf("format %q is already registered", name)
+		}
+	}
+	formats = append(formats, f)
+	return nil
+}
+
+// Formats returns the registered formats, in the order they were registered.
+func Formats() []Format {
+	return append([]Format(nil), formats...)
+}
+
+// Lookup finds a format by name or alias, falling back to an external
+// exporter on PATH.
+func Lookup(name string) (Format, error) {
+	if f, ok := lookupBuiltin(name); ok {
+		return f, nil
+	}
+	if f, ok := External(name); ok {
+		return f, nil
+	}
+	return Format{}, fmt.Errorf("unknown format: %s (see --list-formats)", name)
+}
+
+func lookupBuiltin(name string) (Format, bool) {
+	for _, f := range formats {
+		if f.Name == name || contains(f.Aliases, name) {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// FormatForPath infers the format from the output path's file name,
+// ignoring case. The longest matching suffix wins, so "x.gitlab-ci.yml" is
+// a GitLab pipeline while "setup.yml" is a GitHub workflow.
+func FormatForPath(path string) (Format, bool) {
+	base := strings.ToLower(filepath.Base(path))
+	var best Format
+	for _, f := range formats {
+		if f.Ext != "" && strings.HasSuffix(base, strings.ToLower(f.Ext)) && len(f.Ext) > len(best.Ext) {
+			best = f
+		}
+	}
+	return best, best.Name != ""
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatForPath(t *testing.T) {
+	tests := map[string]string{
+		"run.sh":              "bash",
+		"docs/RUNBOOK.md":     "md",
+		"Makefile":            "make",
+		"GNUmakefile":         "make",
+		"justfile":            "just",
+		"setup.yml":           "gha",
+		"ci/.gitlab-ci.yml":   "gitlab-ci",
+		"Dockerfile":          "dockerfile",
+		"build.Dockerfile":    "dockerfile",
+		"notes.txt":           "",
+		"Makefile.bak/run.sh": "bash",
+	}
+	for path, want := range tests {
+		f, ok := FormatForPath(path)
+		if f.Name != want || ok != (want != "") {
+			t.Errorf("FormatForPath(%q) = %q, %v; want %q", path, f.Name, ok, want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	f, err := Lookup("markdown")
+	if err != nil || f.Name != "md" {
+		t.Errorf("Lookup(markdown) = %q, %v", f.Name, err)
+	}
+	if _, err := Lookup("nope"); err == nil {
+		t.Error("Lookup(nope) succeeded")
+	}
+	if err := Register(Format{Name: "other", Aliases: []string{"bash"}}); err == nil {
+		t.Error("Register accepted a taken alias")
+	}
+}
+
+func TestExternalExporter(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir+string(filepath.ListSeparator)+os.Getenv("PATH"))
+	script := "#!/bin/sh\ncat > \"$0.in\"\necho exported\n"
+	if err := os.WriteFile(filepath.Join(dir, ExternalPrefix+"echo"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Names of built-in formats cannot be taken over.
+	if err := os.WriteFile(filepath.Join(dir, ExternalPrefix+"bash"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range ExternalFormats() {
+		names = append(names, f.Name)
+	}
+	if !contains(names, "echo") || contains(names, "bash") {
+		t.Errorf("ExternalFormats() = %v", names)
+	}
+	f, err := Lookup("echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := exportString(t, f.Exporter.Export, secretSelection(), Options{}); out != "exported\n" {
+		t.Errorf("output = %q", out)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ExternalPrefix+"echo.in"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "ghp_") {
+		t.Errorf("external exporter saw a secret:\n%s", data)
+	}
+	var got externalInput
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Items[0].Cmd != `export GITHUB_TOKEN="${GITHUB_TOKEN:?must be set}"` {
+		t.Errorf("command = %q", got.Items[0].Cmd)
+	}
+	if want := []string{"GITHUB_TOKEN", "TOKEN", "GITHUB_TOKEN_2"}; !reflect.DeepEqual(got.Required, want) {
+		t.Errorf("required = %q, want %q", got.Required, want)
+	}
+}
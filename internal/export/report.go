@@ -0,0 +1,107 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// Redaction records one value removed from an export. It never holds the
+// value itself, only where it was.
+type Redaction struct {
+	// Step is the item's position in the selection, starting at 1.
+	Step int    `json:"step"`
+	Rule string `json:"rule"`
+	// Start and End are byte offsets in the recorded command or note.
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// Variable is the required variable replacing the value in scripts;
+	// notes are redacted with a placeholder and have none.
+	Variable string `json:"variable,omitempty"`
+}
+
+// Suspect is a random-looking string that no rule redacted.
+type Suspect struct {
+	Step  int `json:"step"`
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// RedactionReport lists what an export of a selection redacts and what it
+// may have missed.
+type RedactionReport struct {
+	Selection  string      `json:"selection"`
+	Redactions []Redaction `json:"redactions"`
+	Suspects   []Suspect   `json:"suspects"`
+}
+
+// BuildRedactionReport applies the same redaction as the exporters to the
+// selection and reports each span removed, plus suspects: strings the
+// high-entropy heuristic flags that no rule covers.
+func BuildRedactionReport(selection pick.Selection, opts Options) RedactionReport {
+	r := opts.redactor()
+	vars := newRequiredVars()
+	report := RedactionReport{Selection: selection.ID, Redactions: []Redaction{}, Suspects: []Suspect{}}
+	for i, item := range selection.Items {
+		for _, s := range r.Find(item.Cmd) {
+			red := Redaction{Step: i + 1, Rule: s.Rule, Start: s.Start, End: s.End}
+			if item.IsCommand() {
+				red.Variable = vars.variable(s)
+			}
+			report.Redactions = append(report.Redactions, red)
+		}
+		for _, s := range r.Suspects(item.Cmd) {
+			report.Suspects = append(report.Suspects, Suspect{Step: i + 1, Start: s.Start, End: s.End})
+		}
+	}
+	return report
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r RedactionReport) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// WriteText writes the report as tables for reading.
+func (r RedactionReport) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Redaction report for selection %s\n\n", r.Selection)
+	if len(r.Redactions) == 0 {
+		fmt.Fprintln(w, "Nothing redacted.")
+	} else {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "STEP\tRULE\tBYTES\tVARIABLE")
+		for _, red := range r.Redactions {
+			fmt.Fprintf(tw, "%d\t%s\t%d-%d\t%s\n", red.Step, red.Rule, red.Start, red.End, orDash(red.Variable))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(r.Suspects) == 0 {
+		fmt.Fprintln(w, "\nNo suspected secrets left unredacted.")
+		return nil
+	}
+	fmt.Fprintf(w, "\n%d suspected secret(s) not redacted:\n", len(r.Suspects))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tBYTES")
+	for _, s := range r.Suspects {
+		fmt.Fprintf(tw, "%d\t%d-%d\n", s.Step, s.Start, s.End)
+	}
+	return tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildRedactionReport(t *testing.T) {
+	report := BuildRedactionReport(secretSelection(), Options{})
+	want := []Redaction{
+		{Step: 1, Rule: "env-assignment", Start: 20, End: 27, Variable: "GITHUB_TOKEN"},
+		{Step: 2, Rule: "secret-flag", Start: 15, End: 22, Variable: "TOKEN"},
+		{Step: 2, Rule: "env-assignment", Start: 40, End: 47, Variable: "GITHUB_TOKEN_2"},
+		{Step: 3, Rule: "env-assignment", Start: 22, End: 29},
+	}
+	if len(report.Redactions) != len(want) {
+		t.Fatalf("Redactions = %+v, want %+v", report.Redactions, want)
+	}
+	for i := range want {
+		if report.Redactions[i] != want[i] {
+			t.Errorf("Redactions[%d] = %+v, want %+v", i, report.Redactions[i], want[i])
+		}
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "ghp_") {
+		t.Errorf("report leaks a secret:\n%s", out.String())
+	}
+	var decoded RedactionReport
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+}
+
+func TestBuildRedactionReportSuspects(t *testing.T) {
+	report := BuildRedactionReport(testSelection("", cmdStep("", "deploy --seed Qm4Rt7Yp2Wx9Kb3Nc8Vf5Hj6")), Options{})
+	if len(report.Suspects) != 1 || report.Suspects[0] != (Suspect{Step: 1, Start: 14, End: 38}) {
+		t.Errorf("Suspects = %+v", report.Suspects)
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "1 suspected secret(s) not redacted") || strings.Contains(out.String(), "Qm4R") {
+		t.Errorf("text report:\n%s", out.String())
+	}
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/redact"
+)
+
+// requiredVars turns redacted secrets into environment variables the
+// exported script requires. The same secret always maps to the same
+// variable; different secrets under the same name get numbered variables.
+type requiredVars struct {
+	names  []string
+	byName map[string]map[string]string // name -> value -> variable
+	used   map[string]bool
+}
+
+func newRequiredVars() *requiredVars {
+	return &requiredVars{byName: make(map[string]map[string]string), used: make(map[string]bool)}
+}
+
+// variable returns the variable for a secret, allocating one if needed.
+func (v *requiredVars) variable(s redact.Secret) string {
+	name := varName(s.Name)
+	if v.byName[name] == nil {
+		v.byName[name] = make(map[string]string)
+	}
+	if existing, ok := v.byName[name][s.Value]; ok {
+		return existing
+	}
+
+	variable := name
+	for n := 2; v.used[variable]; n++ {
+		variable = fmt.Sprintf("%s_%d", name, n)
+	}
+	v.used[variable] = true
+	v.byName[name][s.Value] = variable
+	v.names = append(v.names, variable)
+	return variable
+}
+
+// redact replaces the secrets in cmd with references that make the script
+// fail if the variable is unset. Each secret's span takes in any quotes
+// that are not around all of it, so outside quotes the reference is quoted
+// afresh.
+func (v *requiredVars) redact(r *redact.Redactor, cmd string) string {
+	return r.RedactFunc(cmd, func(s redact.Secret) string {
+		ref := fmt.Sprintf("${%s:?must be set}", v.variable(s))
+		switch s.Quote {
+		case '"':
+			return ref
+		case '\'':
+			// Close the single quotes around the reference so it expands.
+			return `'"` + ref + `"'`
+		}
+		return `"` + ref + `"`
+	})
+}
+
+// varName derives an environment variable name from a key or flag name.
+func varName(name string) string {
+	if name == "" {
+		return "SECRET"
+	}
+	name = strings.ToUpper(strings.TrimLeft(name, "-"))
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" || isDigit(name[0]) {
+		name = "SECRET_" + name
+	}
+	return name
+}
+
+// redactCommands redacts every command in the selection, turning secrets
+// into required variables. It returns the redacted commands by item index
+// (notes and markers are left out) and the variables in order of first use.
+func redactCommands(selection pick.Selection, opts Options) (map[int]string, []string) {
+	r := opts.redactor()
+	vars := newRequiredVars()
+	cmds := make(map[int]string)
+	for i, item := range selection.Items {
+		if item.Type == events.TypeNote || item.Type == events.TypeMarker {
+			continue
+		}
+		cmds[i] = substitute(vars.redact(r, item.Cmd), opts.Params)
+	}
+	return cmds, vars.names
+}
+
+// redactAnnotations returns a copy of the selection with secrets removed
+// from its description, its notes and markers, and each item's section,
+// title and note, which are written by hand and may quote a token.
+func redactAnnotations(selection pick.Selection, opts Options) pick.Selection {
+	r := opts.redactor()
+	selection.Description = r.Redact(selection.Description)
+	items := make([]pick.Item, len(selection.Items))
+	for i, item := range selection.Items {
+		item.Section = r.Redact(item.Section)
+		item.Title = r.Redact(item.Title)
+		item.Note = r.Redact(item.Note)
+		if !item.IsCommand() {
+			item.Cmd = r.Redact(item.Cmd)
+		}
+		items[i] = item
+	}
+	selection.Items = items
+	return selection
+}
@@ -0,0 +1,180 @@
+package export
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/redact"
+)
+
+// task is one make target or just recipe: the commands of one step, or of
+// consecutive steps in the same directory when grouping.
+type task struct {
+	name string
+	// doc holds the titles, notes and markers before the task's first
+	// step, written as comments above it.
+	doc []string
+	// cwd is the directory to cd into, with params substituted.
+	cwd string
+	// body holds the commands, preceded by "# " comment lines for the
+	// annotations of later steps in a group.
+	body []string
+}
+
+// aggregateTask is the name of the task running every other task in order.
+const aggregateTask = "all"
+
+var (
+	taskWordRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+	taskSlugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+	// taskSkipWords are not useful in a task name.
+	taskSkipWords = map[string]bool{"sudo": true, "env": true, "time": true, "exec": true, "nohup": true}
+	// taskKeywords are make directives and just keywords that cannot name a
+	// task.
+	taskKeywords = []string{
+		"define", "else", "endef", "endif", "export", "ifdef", "ifeq", "ifndef", "ifneq",
+		"include", "override", "unexport", "vpath", "alias", "import", "mod", "set",
+	}
+)
+
+// buildTasks splits the selection into tasks. A task's name comes from the
+// step's title, or a section heading when grouping, and otherwise from the
+// command or directory. Names are unique and never clash with the aggregate.
+func buildTasks(selection pick.Selection, opts Options) ([]task, []string) {
+	selection = redactAnnotations(selection, opts)
+	cmds, required := redactCommands(selection, opts)
+	var (
+		tasks    []task
+		comments []string
+		cur      *task
+		curCwd   string
+	)
+	for i, item := range selection.Items {
+		if item.Section != "" {
+			comments = append(comments, item.Section)
+			cur = nil
+		}
+		switch item.Type {
+		case events.TypeNote:
+			comments = append(comments, item.Cmd)
+			continue
+		case events.TypeMarker:
+			comments = append(comments, fmt.Sprintf("=== %s ===", item.Cmd))
+			continue
+		}
+
+		if cur == nil || !opts.GroupByCwd || item.Cwd != curCwd {
+			name := item.Title
+			switch {
+			case name == "" && opts.GroupByCwd && item.Section != "":
+				name = item.Section
+			case name == "" && opts.GroupByCwd:
+				name = path.Base(item.Cwd)
+			case name == "":
+				name = commandName(cmds[i])
+			}
+			tasks = append(tasks, task{name: name, cwd: substitute(item.Cwd, opts.Params)})
+			cur = &tasks[len(tasks)-1]
+			curCwd = item.Cwd
+		}
+		if item.Title != "" {
+			comments = append(comments, item.Title)
+		}
+		if item.Note != "" {
+			comments = append(comments, strings.Split(item.Note, "\n")...)
+		}
+		if len(cur.body) == 0 {
+			cur.doc = comments
+		} else {
+			cur.body = append(cur.body, commentLines(comments)...)
+		}
+		comments = nil
+		cur.body = append(cur.body, cmds[i])
+	}
+	if len(tasks) > 0 {
+		last := &tasks[len(tasks)-1]
+		last.body = append(last.body, commentLines(comments)...)
+	}
+
+	names := make([]string, len(tasks))
+	for i, t := range tasks {
+		names[i] = t.name
+	}
+	for i, name := range uniqueNames(names, append([]string{aggregateTask}, taskKeywords...)) {
+		tasks[i].name = name
+	}
+	return tasks, required
+}
+
+// commentLines turns comments into "# " lines for a recipe body, splitting
+// multi-line ones so that no line escapes the comment.
+func commentLines(comments []string) []string {
+	var lines []string
+	for _, c := range comments {
+		for _, line := range strings.Split(c, "\n") {
+			lines = append(lines, "# "+line)
+		}
+	}
+	return lines
+}
+
+// uniqueNames turns names into slugs that differ from each other and from
+// reserved, numbering repeats ("build-2") and naming empty ones after their
+// position ("step-3").
+func uniqueNames(names, reserved []string) []string {
+	taken := make(map[string]bool)
+	for _, r := range reserved {
+		taken[r] = true
+	}
+	out := make([]string, len(names))
+	for i, name := range names {
+		name = taskSlug(name)
+		if name == "" {
+			name = fmt.Sprintf("step-%d", i+1)
+		}
+		base := name
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s-%d", base, n)
+		}
+		taken[name] = true
+		out[i] = name
+	}
+	return out
+}
+
+// commandName derives a task name from the leading words of a command,
+// e.g. "go-test" from "go test ./...".
+func commandName(cmd string) string {
+	cmds := redact.Tokenize(cmd)
+	if len(cmds) == 0 {
+		return ""
+	}
+	var words []string
+	for _, w := range cmds[0] {
+		switch {
+		case strings.Contains(w.Value, "=") || taskSkipWords[w.Value]:
+			continue
+		case !taskWordRegex.MatchString(w.Value) || len(words) == 3:
+			return strings.Join(words, "-")
+		}
+		words = append(words, w.Value)
+	}
+	return strings.Join(words, "-")
+}
+
+// taskSlug turns a title into a name make and just both accept: lower-case
+// letters, digits and dashes, starting with a letter.
+func taskSlug(s string) string {
+	s = strings.Trim(taskSlugRegex.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(s) > 40 {
+		s = strings.TrimRight(s[:40], "-")
+	}
+	if s != "" && isDigit(s[0]) {
+		s = "step-" + s
+	}
+	return s
+}
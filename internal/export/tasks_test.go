@@ -0,0 +1,110 @@
+package export
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+func taskSelection(dir string) pick.Selection {
+	selection := testSelection(dir,
+		cmdStep(dir, `echo "home is $HOME" > out.txt`),
+		noteStep("then build"),
+		cmdStep(dir, "go build ./... || true\necho '{{ not a template }}' >> out.txt"),
+		cmdStep(filepath.Join(dir, "sub"), "go build ./... || true"),
+	)
+	selection.Scope = "repo"
+	selection.Items[3].Title = "Build sub module"
+	return selection
+}
+
+func TestBuildTasks(t *testing.T) {
+	tasks, _ := buildTasks(taskSelection("/repo"), Options{})
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.name)
+	}
+	if got, want := strings.Join(names, " "), "echo go-build build-sub-module"; got != want {
+		t.Errorf("names = %q, want %q", got, want)
+	}
+	if len(tasks[1].doc) != 1 || tasks[1].doc[0] != "then build" {
+		t.Errorf("doc = %q", tasks[1].doc)
+	}
+
+	tasks, _ = buildTasks(taskSelection("/repo"), Options{GroupByCwd: true})
+	if len(tasks) != 2 || tasks[0].name != "repo" || len(tasks[0].body) != 3 || tasks[0].body[1] != "# then build" {
+		t.Errorf("grouped tasks = %+v", tasks)
+	}
+}
+
+func TestTaskHeaderKeepsTextInComments(t *testing.T) {
+	selection := taskSelection("/repo")
+	selection.Description = "first line\nevil:\n\trm -rf /tmp/x"
+	selection.Params = []pick.Param{{Name: "DIR", Value: "/repo", Description: "dir\nevil2:"}}
+	for name, exporter := range map[string]ExportFunc{"make": MakeExporter, "just": JustExporter} {
+		out := exportString(t, exporter, selection, Options{Params: selection.Params})
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, "evil") || strings.HasPrefix(line, "\trm") {
+				t.Errorf("%s: header text escaped its comment: %q\n%s", name, line, out)
+			}
+		}
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	tests := map[string]string{
+		"go test ./...":             "go-test",
+		"npm run build --prod":      "npm-run-build",
+		"FOO=1 sudo make install":   "make-install",
+		"docker compose up -d db":   "docker-compose-up",
+		"./configure --prefix=/usr": "",
+	}
+	for cmd, want := range tests {
+		if got := commandName(cmd); got != want {
+			t.Errorf("commandName(%q) = %q, want %q", cmd, got, want)
+		}
+	}
+}
+
+func TestMakeExporterRuns(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make not installed")
+	}
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	out := exportString(t, MakeExporter, taskSelection(dir), Options{})
+	assertContains(t, out, `echo "home is $$HOME"`)
+	makefile := filepath.Join(dir, "Makefile")
+	os.WriteFile(makefile, []byte(out), 0644)
+
+	if msg, err := exec.Command("make", "-s", "-f", makefile, "-C", t.TempDir()).CombinedOutput(); err != nil {
+		t.Fatalf("make failed: %v\n%s\n%s", err, msg, out)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if want := "home is " + os.Getenv("HOME") + "\n{{ not a template }}\n"; string(got) != want {
+		t.Errorf("out.txt = %q, want %q", got, want)
+	}
+}
+
+func TestJustExporterRuns(t *testing.T) {
+	if _, err := exec.LookPath("just"); err != nil {
+		t.Skip("just not installed")
+	}
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	out := exportString(t, JustExporter, taskSelection(dir), Options{GroupByCwd: true})
+	justfile := filepath.Join(dir, "justfile")
+	os.WriteFile(justfile, []byte(out), 0644)
+
+	if msg, err := exec.Command("just", "--justfile", justfile, "--working-directory", t.TempDir()).CombinedOutput(); err != nil {
+		t.Fatalf("just failed: %v\n%s\n%s", err, msg, out)
+	}
+	got, _ := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if want := "home is " + os.Getenv("HOME") + "\n{{ not a template }}\n"; string(got) != want {
+		t.Errorf("out.txt = %q, want %q", got, want)
+	}
+}
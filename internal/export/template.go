@@ -0,0 +1,218 @@
+package export
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/shell"
+)
+
+// TemplateExt is the file extension of export templates.
+const TemplateExt = ".tmpl"
+
+//go:embed templates/*.tmpl
+var shippedTemplates embed.FS
+
+// TemplateData is what export templates are executed with.
+type TemplateData struct {
+	Selection pick.Selection
+	// Now is the time of the export.
+	Now time.Time
+	// Required lists the variables replacing redacted secrets, in order of
+	// first use.
+	Required []string
+	// Params are the parameters hoisted into variables.
+	Params []pick.Param
+	// Steps are the selection's items, including notes and markers.
+	Steps []TemplateStep
+}
+
+// TemplateStep is one item of the selection as seen by templates.
+type TemplateStep struct {
+	pick.Item
+	// Number is the item's position in the selection, starting at 1.
+	Number int
+	// Name is a unique slug for a command step, from its title or the
+	// command itself, e.g. "go-test". It is empty for notes and markers.
+	Name string
+	// Command is the command with secrets replaced by required variables
+	// and params substituted. For notes it is the redacted note text, for
+	// markers the label.
+	Command string
+}
+
+// StepGroup is a run of consecutive commands in the same directory, with
+// the notes and markers between them.
+type StepGroup struct {
+	Cwd   string
+	Steps []TemplateStep
+}
+
+// ShippedTemplates returns the names of the templates built into cmdsetgo.
+func ShippedTemplates() []string {
+	entries, _ := shippedTemplates.ReadDir("templates")
+	var names []string
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), TemplateExt))
+	}
+	return names
+}
+
+// ShippedTemplate returns the source of a built-in template.
+func ShippedTemplate(name string) (string, error) {
+	data, err := shippedTemplates.ReadFile(path.Join("templates", name+TemplateExt))
+	if err != nil {
+		return "", fmt.Errorf("no built-in template %q", name)
+	}
+	return string(data), nil
+}
+
+// ParseTemplate parses an export template. The built-in templates are
+// available to it by name, e.g. {{template "bash" .}}.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	root := template.New(name).Funcs(templateFuncs(Options{}, pick.Selection{}))
+	for _, shipped := range ShippedTemplates() {
+		if shipped == name {
+			continue
+		}
+		src, err := ShippedTemplate(shipped)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := root.New(shipped).Parse(src); err != nil {
+			return nil, fmt.Errorf("built-in template %s: %w", shipped, err)
+		}
+	}
+	if _, err := root.Parse(text); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// TemplateExporter renders the selection with tmpl (see ParseTemplate),
+// applying the same redaction and parameters as the built-in formats.
+func TemplateExporter(w io.Writer, tmpl *template.Template, selection pick.Selection, opts Options) error {
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(templateFuncs(opts, selection))
+	return tmpl.Execute(w, newTemplateData(selection, opts))
+}
+
+// renderShipped renders a built-in template.
+func renderShipped(w io.Writer, name string, selection pick.Selection, opts Options) error {
+	src, err := ShippedTemplate(name)
+	if err != nil {
+		return err
+	}
+	tmpl, err := ParseTemplate(name, src)
+	if err != nil {
+		return err
+	}
+	return TemplateExporter(w, tmpl, selection, opts)
+}
+
+func newTemplateData(selection pick.Selection, opts Options) TemplateData {
+	selection = redactAnnotations(selection, opts)
+	cmds, required := redactCommands(selection, opts)
+	data := TemplateData{Selection: selection, Now: time.Now(), Required: required, Params: opts.Params}
+	var names []string
+	for i, item := range selection.Items {
+		step := TemplateStep{Item: item, Number: i + 1, Command: cmds[i]}
+		if !item.IsCommand() {
+			step.Command = item.Cmd
+		} else if item.Title != "" {
+			names = append(names, item.Title)
+		} else {
+			names = append(names, commandName(cmds[i]))
+		}
+		data.Steps = append(data.Steps, step)
+	}
+	names = uniqueNames(names, nil)
+	for i := range data.Steps {
+		if data.Steps[i].IsCommand() {
+			data.Steps[i].Name, names = names[0], names[1:]
+		}
+	}
+	return data
+}
+
+// templateFuncs returns the helper functions available to templates.
+func templateFuncs(opts Options, selection pick.Selection) template.FuncMap {
+	return template.FuncMap{
+		// redact replaces secrets in any text with ***REDACTED***.
+		"redact": func(s string) string { return opts.redactor().Redact(s) },
+		// substitute replaces param values with ${NAME} references.
+		"substitute": func(s string) string { return substitute(s, opts.Params) },
+		"shellquote": shell.Quote,
+		// shelldefault escapes a value for "${NAME:-value}".
+		"shelldefault": shellDefault,
+		// relcwd makes a directory relative to the repo root.
+		"relcwd": func(cwd string) string { return relativeCwd(selection.RepoRoot, cwd) },
+		"groups": groupSteps,
+		// comment turns text into shell comment lines.
+		"comment": func(s string) string {
+			if s == "" {
+				return ""
+			}
+			return "# " + strings.ReplaceAll(s, "\n", "\n# ")
+		},
+		"tablecell": tableCell,
+		"oneline":   oneLine,
+		"lines":     func(s string) []string { return strings.Split(s, "\n") },
+		"join":      strings.Join,
+		// json encodes a value as compact JSON.
+		"json": func(v any) (string, error) {
+			var b strings.Builder
+			encoder := json.NewEncoder(&b)
+			encoder.SetEscapeHTML(false)
+			err := encoder.Encode(v)
+			return strings.TrimSuffix(b.String(), "\n"), err
+		},
+	}
+}
+
+// oneLine joins the lines of s with spaces, for headings and other
+// single-line places.
+func oneLine(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// groupSteps splits steps into runs of consecutive commands in the same
+// directory. Notes and markers join the group of the command after them.
+func groupSteps(steps []TemplateStep) []StepGroup {
+	var groups []StepGroup
+	var pending []TemplateStep
+	for _, step := range steps {
+		if !step.IsCommand() {
+			pending = append(pending, step)
+			continue
+		}
+		section := step.Section != ""
+		for _, p := range pending {
+			section = section || p.Section != ""
+		}
+		if len(groups) == 0 || section || groups[len(groups)-1].Cwd != step.Cwd {
+			groups = append(groups, StepGroup{Cwd: step.Cwd})
+		}
+		g := &groups[len(groups)-1]
+		g.Steps = append(append(g.Steps, pending...), step)
+		pending = nil
+	}
+	if len(pending) > 0 {
+		if len(groups) == 0 {
+			groups = append(groups, StepGroup{})
+		}
+		g := &groups[len(groups)-1]
+		g.Steps = append(g.Steps, pending...)
+	}
+	return groups
+}
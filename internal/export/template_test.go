@@ -0,0 +1,80 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTemplateExporter(t *testing.T) {
+	tmpl, err := ParseTemplate("steps", `{{range groups .Steps}}[{{relcwd .Cwd}}]
+{{range .Steps}}{{.Number}} {{.Type}} {{.Command}} {{shellquote .Command}}
+{{end}}{{end}}{{redact "key sk-ant-REDACTED"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selection := secretSelection()
+	selection.Items = append(selection.Items, cmdStep("/repo/web", "npm test"))
+
+	var out bytes.Buffer
+	if err := TemplateExporter(&out, tmpl, selection, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	want := `[.]
+1 cmd export GITHUB_TOKEN="${GITHUB_TOKEN:?must be set}" 'export GITHUB_TOKEN="${GITHUB_TOKEN:?must be set}"'
+2 cmd gh api --token "${TOKEN:?must be set}" && GITHUB_TOKEN="${GITHUB_TOKEN_2:?must be set}" gh pr list 'gh api --token "${TOKEN:?must be set}" && GITHUB_TOKEN="${GITHUB_TOKEN_2:?must be set}" gh pr list'
+[web]
+3 note token is GITHUB_TOKEN=***REDACTED*** 'token is GITHUB_TOKEN=***REDACTED***'
+4 cmd npm test 'npm test'
+key ***REDACTED***`
+	if got := out.String(); got != want {
+		t.Errorf("output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTemplateIncludesShipped(t *testing.T) {
+	tmpl, err := ParseTemplate("wrapped", `# custom header
+{{template "bash" .}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out, bash bytes.Buffer
+	if err := TemplateExporter(&out, tmpl, secretSelection(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := BashExporter(&bash, secretSelection(), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := strings.CutPrefix(out.String(), "# custom header\n")
+	if !ok || !strings.Contains(got, `: "${GITHUB_TOKEN:?must be set}"`) {
+		t.Errorf("output:\n%s", out.String())
+	}
+	// Only the timestamp line may differ between the two renders.
+	if len(strings.Split(got, "\n")) != len(strings.Split(bash.String(), "\n")) {
+		t.Errorf("included template differs from BashExporter:\n%s\n---\n%s", got, bash.String())
+	}
+}
+
+func TestGroupSteps(t *testing.T) {
+	steps := []TemplateStep{
+		{Item: noteStep("first")},
+		{Item: cmdStep("/a", "one")},
+		{Item: cmdStep("/a", "two")},
+		{Item: cmdStep("/b", "three")},
+		{Item: cmdStep("/b", "four")},
+		{Item: markerStep("done")},
+	}
+	steps[4].Section = "Deploy"
+	var got []string
+	for _, g := range groupSteps(steps) {
+		var cmds []string
+		for _, s := range g.Steps {
+			cmds = append(cmds, s.Cmd)
+		}
+		got = append(got, g.Cwd+":"+strings.Join(cmds, ","))
+	}
+	want := "/a:first,one,two /b:three /b:four,done"
+	if strings.Join(got, " ") != want {
+		t.Errorf("groups = %v, want %s", got, want)
+	}
+}
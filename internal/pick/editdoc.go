@@ -0,0 +1,224 @@
+package pick
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+const editDocHelp = `# Edit the steps below; the order of lines is the export order.
+#   - One command per line. Delete a line to drop a step. Further lines of
+#     a multi-line command start with "> ", and a command starting with
+#     '#', '@', '>' or '\' is written with an extra '\' in front.
+#   - "@cd <dir>" sets the working directory for the steps after it.
+#     Relative directories are resolved against the repo root.
+#   - "@section <heading>", "@title <heading>" and "@note <text>" annotate
+#     the next step. Repeat them to write several lines.
+#   - The trailing "#id:..." tag links a step to its recorded event so its
+#     time and exit code are kept. New lines without a tag become new steps.
+#   - Lines starting with '#' are comments and are ignored.
+`
+
+var stepIDTagRegex = regexp.MustCompile(`\s+#id:([0-9a-f]{10})$`)
+
+const (
+	// editDocContinuation starts the further lines of a multi-line command.
+	editDocContinuation = "> "
+	// editDocEscape starts a command whose first character would otherwise
+	// make it a comment, directive or continuation.
+	editDocEscape = `\`
+)
+
+// FormatEditDocument renders a selection as a line-per-step text document
+// for editing in $EDITOR. ParseEditDocument reverses it.
+func FormatEditDocument(selection Selection) string {
+	var b strings.Builder
+	title := selection.ID
+	if selection.Name != "" {
+		title = selection.Name + " (" + selection.ID + ")"
+	}
+	fmt.Fprintf(&b, "# Selection: %s\n", title)
+	if selection.RepoRoot != "" {
+		fmt.Fprintf(&b, "# Repo: %s\n", selection.RepoRoot)
+	}
+	b.WriteString(editDocHelp)
+
+	currentCwd := ""
+	for i, item := range selection.Items {
+		if item.Cwd != currentCwd || i == 0 || item.Section != "" {
+			b.WriteString("\n")
+		}
+		writeDirectiveLines(&b, "@section", item.Section)
+		if item.Cwd != currentCwd || i == 0 {
+			fmt.Fprintf(&b, "@cd %s\n", item.Cwd)
+			currentCwd = item.Cwd
+		}
+		writeDirectiveLines(&b, "@title", item.Title)
+		writeDirectiveLines(&b, "@note", item.Note)
+		fmt.Fprintf(&b, "%s  #id:%s\n", formatEditCommand(item.Cmd), item.ID())
+	}
+	return b.String()
+}
+
+// writeDirectiveLines writes a directive for each non-empty line of text.
+func writeDirectiveLines(b *strings.Builder, directive, text string) {
+	for _, line := range strings.Split(text, "\n") {
+		if line != "" {
+			fmt.Fprintf(b, "%s %s\n", directive, line)
+		}
+	}
+}
+
+// formatEditCommand escapes a command that ParseEditDocument would read as
+// something else and marks its further lines as continuations.
+func formatEditCommand(cmd string) string {
+	cmd = strings.TrimLeft(cmd, " \t")
+	if strings.IndexAny(cmd, "#@>"+editDocEscape) == 0 {
+		cmd = editDocEscape + cmd
+	}
+	return strings.ReplaceAll(cmd, "\n", "\n"+editDocContinuation)
+}
+
+// ParseEditDocument parses an edited document back into selection items.
+// Tagged lines must refer to items in original. Errors carry the line
+// number so the user can fix the document and try again.
+func ParseEditDocument(doc string, original Selection) ([]Item, error) {
+	byID := make(map[string]Item, len(original.Items))
+	for _, item := range original.Items {
+		byID[item.ID()] = item
+	}
+
+	var items []Item
+	var pending Item // annotations waiting for the next step
+	var cmd []string // lines of the command being read
+	cmdLineNo := 0
+	cwd := original.RepoRoot
+	// finish turns the command read so far into a step.
+	finish := func() error {
+		if cmd == nil {
+			return nil
+		}
+		line := strings.Join(cmd, "\n")
+		cmd = nil
+
+		var item Item
+		if m := stepIDTagRegex.FindStringSubmatchIndex(line); m != nil {
+			id := line[m[2]:m[3]]
+			orig, ok := byID[id]
+			if !ok {
+				return fmt.Errorf("line %d: unknown step id %s", cmdLineNo, id)
+			}
+			item.CmdEvent = orig.CmdEvent
+			line = strings.TrimSpace(line[:m[0]])
+		} else {
+			item.Type = events.TypeCmd
+		}
+
+		if line == "" {
+			return fmt.Errorf("line %d: empty command", cmdLineNo)
+		}
+		if cwd == "" {
+			return fmt.Errorf("line %d: no working directory; add an \"@cd <dir>\" line first", cmdLineNo)
+		}
+		item.Cmd = line
+		item.Cwd = cwd
+		item.Section, item.Title, item.Note = pending.Section, pending.Title, pending.Note
+		pending = Item{}
+		items = append(items, item)
+		return nil
+	}
+
+	for n, raw := range strings.Split(doc, "\n") {
+		lineNo := n + 1
+		line := strings.TrimSpace(raw)
+		if line == strings.TrimSpace(editDocContinuation) || strings.HasPrefix(line, editDocContinuation) {
+			if cmd == nil {
+				return nil, fmt.Errorf("line %d: continuation line without a command", lineNo)
+			}
+			cmd = append(cmd, strings.TrimPrefix(line[1:], " "))
+			continue
+		}
+		if err := finish(); err != nil {
+			return nil, err
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "@") {
+			directive, arg, _ := strings.Cut(line, " ")
+			arg = strings.TrimSpace(arg)
+			if arg == "" {
+				return nil, fmt.Errorf("line %d: %s needs a value", lineNo, directive)
+			}
+			switch directive {
+			case "@cd":
+				if !filepath.IsAbs(arg) {
+					if original.RepoRoot == "" {
+						return nil, fmt.Errorf("line %d: relative directory %q needs a repo root", lineNo, arg)
+					}
+					arg = filepath.Join(original.RepoRoot, arg)
+				}
+				cwd = arg
+			case "@section":
+				pending.Section = appendLine(pending.Section, arg)
+			case "@title":
+				pending.Title = appendLine(pending.Title, arg)
+			case "@note":
+				pending.Note = appendLine(pending.Note, arg)
+			default:
+				return nil, fmt.Errorf("line %d: unknown directive %q (expected @cd, @section, @title or @note)", lineNo, directive)
+			}
+			continue
+		}
+
+		cmd = []string{strings.TrimPrefix(line, editDocEscape)}
+		cmdLineNo = lineNo
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+
+	if pending.Section != "" || pending.Title != "" || pending.Note != "" {
+		return nil, fmt.Errorf("annotations at the end of the document have no step to attach to")
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("document contains no steps")
+	}
+	return items, nil
+}
+
+// appendLine adds line to text as a line of its own.
+func appendLine(text, line string) string {
+	if text == "" {
+		return line
+	}
+	return text + "\n" + line
+}
+
+// MergeSelections concatenates the items of the given selections into a new,
+// unsaved selection. Repo root and scope come from the first selection, and
+// tags are combined without duplicates.
+func MergeSelections(selections ...Selection) Selection {
+	var merged Selection
+	if len(selections) == 0 {
+		return merged
+	}
+	merged.Scope = selections[0].Scope
+	merged.RepoRoot = selections[0].RepoRoot
+
+	seenTags := make(map[string]bool)
+	for _, s := range selections {
+		merged.Items = append(merged.Items, s.Items...)
+		for _, tag := range s.Tags {
+			if !seenTags[tag] {
+				merged.Tags = append(merged.Tags, tag)
+				seenTags[tag] = true
+			}
+		}
+	}
+	return merged
+}
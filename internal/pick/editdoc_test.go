@@ -0,0 +1,142 @@
+package pick
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+func editSelection() Selection {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return Selection{
+		ID:       "20240102-030405",
+		RepoRoot: "/repo",
+		Items: NewItems([]events.CmdEvent{
+			{Ts: ts, Cwd: "/repo", Cmd: "make deps", Exit: 0},
+			{Ts: ts.Add(time.Second), Cwd: "/repo/web", Cmd: "npm test", Exit: 1},
+		}),
+	}
+}
+
+func TestEditDocumentRoundTrip(t *testing.T) {
+	annotated := editSelection()
+	annotated.Items[0].Section = "Setup"
+	annotated.Items[0].Title = "Install dependencies"
+	annotated.Items[1].Note = "Runs the web tests.\nNeeds node 20."
+
+	// Lines that would read as comments, directives or further steps.
+	awkward := editSelection()
+	awkward.Items = NewItems([]events.CmdEvent{
+		{Cwd: "/repo", Cmd: "cat > x <<EOF\n# not a comment\n@cd /tmp\n\n> still the heredoc\nEOF"},
+		{Cwd: "/repo", Cmd: "#not-a-comment"},
+		{Cwd: "/repo", Cmd: "@not-a-directive"},
+		{Cwd: "/repo", Cmd: "> truncated.log"},
+		{Cwd: "/repo", Cmd: `\ls -l`},
+	})
+	awkward.Items[0].Title = "Write x\nmake ignored"
+	awkward.Items[1].Section = "Section\nrm -rf /"
+
+	tests := []struct {
+		name      string
+		selection Selection
+	}{
+		{"plain", editSelection()},
+		{"annotated", annotated},
+		{"awkward lines", awkward},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := ParseEditDocument(FormatEditDocument(tt.selection), tt.selection)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(items, tt.selection.Items) {
+				t.Errorf("round trip = %+v, want %+v", items, tt.selection.Items)
+			}
+		})
+	}
+}
+
+func TestParseEditDocumentChanges(t *testing.T) {
+	sel := editSelection()
+	doc := FormatEditDocument(sel)
+	lines := strings.Split(doc, "\n")
+
+	// Swap the two steps, edit one and add a new step in a relative dir.
+	var out []string
+	var first, second string
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "make deps"):
+			first = l
+		case strings.HasPrefix(l, "npm test"):
+			second = strings.Replace(l, "npm test", "npm test -- --ci", 1)
+		case strings.HasPrefix(l, "@cd"):
+		default:
+			out = append(out, l)
+		}
+	}
+	out = append(out, "@cd web", second, "@cd /repo", first, "make build")
+
+	items, err := ParseEditDocument(strings.Join(out, "\n"), sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]string, len(items))
+	for i, ev := range items {
+		got[i] = ev.Cwd + ": " + ev.Cmd
+	}
+	want := []string{"/repo/web: npm test -- --ci", "/repo: make deps", "/repo: make build"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("items = %v, want %v", got, want)
+	}
+	if items[0].Exit != 1 || !items[0].Ts.Equal(sel.Items[1].Ts) {
+		t.Errorf("edited step lost its recorded metadata: %+v", items[0])
+	}
+	if !items[2].Ts.IsZero() || items[2].Type != events.TypeCmd {
+		t.Errorf("new step = %+v", items[2])
+	}
+}
+
+func TestParseEditDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoRoot string
+		doc      string
+		want     string
+	}{
+		{"bad directive", "/repo", "@pushd /tmp\nls", `line 1: unknown directive "@pushd" (expected @cd, @section, @title or @note)`},
+		{"unknown id", "/repo", "make deps  #id:0123456789", "line 1: unknown step id 0123456789"},
+		{"empty", "/repo", "# nothing here\n", "document contains no steps"},
+		{"no repo root", "", "@cd rel\nls", `line 1: relative directory "rel" needs a repo root`},
+		{"no working directory", "", "ls", `line 1: no working directory; add an "@cd <dir>" line first`},
+		{"stray continuation", "/repo", "# comment\n> EOF", "line 2: continuation line without a command"},
+		{"dangling annotation", "/repo", "ls\n@title dangling", "annotations at the end of the document have no step to attach to"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel := editSelection()
+			sel.RepoRoot = tt.repoRoot
+			_, err := ParseEditDocument(tt.doc, sel)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("ParseEditDocument() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSelections(t *testing.T) {
+	a := Selection{RepoRoot: "/a", Tags: []string{"ci"}, Items: NewItems([]events.CmdEvent{{Cmd: "one"}})}
+	b := Selection{RepoRoot: "/b", Tags: []string{"ci", "db"}, Items: NewItems([]events.CmdEvent{{Cmd: "two"}})}
+	merged := MergeSelections(a, b)
+	if merged.RepoRoot != "/a" || len(merged.Items) != 2 || merged.Items[1].Cmd != "two" {
+		t.Errorf("MergeSelections() = %+v", merged)
+	}
+	if !reflect.DeepEqual(merged.Tags, []string{"ci", "db"}) {
+		t.Errorf("merged tags = %v", merged.Tags)
+	}
+}
@@ -0,0 +1,80 @@
+package pick
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+// FuzzyMatch reports whether every rune of pattern appears in s in order
+// (case-insensitive) and returns a score where higher means a better match.
+// Consecutive runs and matches at word boundaries score higher.
+// An empty pattern matches everything with a score of 0.
+func FuzzyMatch(pattern, s string) (int, bool) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return 0, true
+	}
+
+	target := []rune(strings.ToLower(s))
+	score := 0
+	pos := 0
+	prevMatched := false
+	for _, pr := range pattern {
+		if unicode.IsSpace(pr) {
+			continue
+		}
+		found := false
+		for pos < len(target) {
+			if target[pos] == pr {
+				score++
+				if prevMatched {
+					score += 2
+				}
+				if pos == 0 || isBoundary(target[pos-1]) {
+					score += 3
+				}
+				pos++
+				found = true
+				prevMatched = true
+				break
+			}
+			pos++
+			prevMatched = false
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+func isBoundary(r rune) bool {
+	return unicode.IsSpace(r) || r == '/' || r == '-' || r == '_' || r == '.'
+}
+
+// FuzzyFilter returns the indices of evs whose command matches pattern,
+// best matches first. Ties keep their original (chronological) order.
+func FuzzyFilter(evs []events.CmdEvent, pattern string) []int {
+	type scored struct {
+		idx   int
+		score int
+	}
+	var matches []scored
+	for i, ev := range evs {
+		if score, ok := FuzzyMatch(pattern, ev.Cmd); ok {
+			matches = append(matches, scored{i, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	indices := make([]int, len(matches))
+	for i, m := range matches {
+		indices[i] = m.idx
+	}
+	return indices
+}
@@ -0,0 +1,51 @@
+package pick
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"", "go test ./...", true},
+		{"gotest", "go test ./...", true},
+		{"GT", "go test ./...", true},
+		{"dkb", "docker build .", true},
+		{"tg", "go test", false},
+		{"make", "go build", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.s, func(t *testing.T) {
+			if _, got := FuzzyMatch(tt.pattern, tt.s); got != tt.want {
+				t.Errorf("FuzzyMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	evs := []events.CmdEvent{
+		{Cmd: "git status"},
+		{Cmd: "go build ./..."},
+		{Cmd: "echo gobble"},
+		{Cmd: "go test ./..."},
+	}
+
+	// Word-boundary matches rank above scattered ones; ties keep log order.
+	got := FuzzyFilter(evs, "gob")
+	want := []int{1, 2}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FuzzyFilter() = %v, want %v", got, want)
+	}
+
+	if got := FuzzyFilter(evs, ""); !reflect.DeepEqual(got, []int{0, 1, 2, 3}) {
+		t.Errorf("FuzzyFilter() with empty pattern = %v", got)
+	}
+}
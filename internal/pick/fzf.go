@@ -0,0 +1,38 @@
+package pick
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+// FzfLines formats events as tab-separated lines for fzf. The first field is
+// the event ID, which fzf hides and hands back on selection.
+func FzfLines(evs []events.CmdEvent) []string {
+	lines := make([]string, 0, len(evs))
+	for _, ev := range evs {
+		// Tabs and newlines would break the field layout fzf relies on.
+		cmd := strings.NewReplacer("\t", " ", "\n", " ").Replace(ev.Label())
+		lines = append(lines, fmt.Sprintf("%s\t%s\t(%d)\t%s", ev.ID(), ev.Ts.Local().Format("15:04:05"), ev.Exit, cmd))
+	}
+	return lines
+}
+
+// ParseFzfOutput maps the lines fzf printed back to events, preserving the
+// order in which they were selected. Unknown IDs are reported as errors.
+func ParseFzfOutput(output string, evs []events.CmdEvent) ([]events.CmdEvent, error) {
+	var selected []events.CmdEvent
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		id, _, _ := strings.Cut(line, "\t")
+		ev, ok := events.FindByID(evs, id)
+		if !ok {
+			return nil, fmt.Errorf("unknown event in fzf output: %s", id)
+		}
+		selected = append(selected, ev)
+	}
+	return selected, nil
+}
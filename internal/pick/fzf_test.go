@@ -0,0 +1,37 @@
+package pick
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+func TestFzfRoundTrip(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	evs := []events.CmdEvent{
+		{Ts: ts, Cwd: "/repo", Cmd: "go build ./..."},
+		{Ts: ts.Add(time.Second), Cwd: "/repo", Cmd: "printf 'a\tb'"},
+		{Ts: ts.Add(2 * time.Second), Cwd: "/repo", Cmd: "go test ./..."},
+	}
+
+	lines := FzfLines(evs)
+	if strings.Count(lines[1], "\t") != 3 {
+		t.Fatalf("embedded tab not escaped: %q", lines[1])
+	}
+
+	// fzf prints selections in the order the user picked them.
+	out := lines[2] + "\n" + lines[0] + "\n"
+	got, err := ParseFzfOutput(out, evs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Cmd != "go test ./..." || got[1].Cmd != "go build ./..." {
+		t.Errorf("ParseFzfOutput() = %v", got)
+	}
+
+	if _, err := ParseFzfOutput("deadbeef00\tx\n", evs); err == nil {
+		t.Error("expected error for unknown ID")
+	}
+}
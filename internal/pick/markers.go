@@ -0,0 +1,43 @@
+package pick
+
+import (
+	"fmt"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+// SliceByMarkers returns the events recorded after the most recent marker
+// labelled since and before the first following marker labelled until.
+// Either label may be empty to leave that end open. The markers themselves
+// are not included.
+func SliceByMarkers(evs []events.CmdEvent, since, until string) ([]events.CmdEvent, error) {
+	start := 0
+	if since != "" {
+		start = -1
+		for i := len(evs) - 1; i >= 0; i-- {
+			if evs[i].Type == events.TypeMarker && evs[i].Cmd == since {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, fmt.Errorf("marker not found: %s", since)
+		}
+	}
+
+	end := len(evs)
+	if until != "" {
+		end = -1
+		for i := start; i < len(evs); i++ {
+			if evs[i].Type == events.TypeMarker && evs[i].Cmd == until {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("marker not found after %q: %s", since, until)
+		}
+	}
+
+	return evs[start:end], nil
+}
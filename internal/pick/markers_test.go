@@ -0,0 +1,45 @@
+package pick
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+func TestSliceByMarkers(t *testing.T) {
+	evs := []events.CmdEvent{
+		{Type: "cmd", Cmd: "make"},
+		{Type: "marker", Cmd: "start-debug"},
+		{Type: "cmd", Cmd: "old attempt"},
+		{Type: "marker", Cmd: "start-debug"},
+		{Type: "cmd", Cmd: "go test"},
+		{Type: "note", Cmd: "flaky on CI"},
+		{Type: "marker", Cmd: "done"},
+		{Type: "cmd", Cmd: "git push"},
+	}
+
+	tests := []struct {
+		since, until string
+		want         []string
+		wantErr      bool
+	}{
+		{"start-debug", "", []string{"go test", "flaky on CI", "done", "git push"}, false},
+		{"start-debug", "done", []string{"go test", "flaky on CI"}, false},
+		{"", "start-debug", []string{"make"}, false},
+		{"missing", "", nil, true},
+		{"done", "start-debug", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.since+".."+tt.until, func(t *testing.T) {
+			got, err := SliceByMarkers(evs, tt.since, tt.until)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SliceByMarkers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(cmdsOf(got), tt.want) {
+				t.Errorf("SliceByMarkers() = %v, want %v", cmdsOf(got), tt.want)
+			}
+		})
+	}
+}
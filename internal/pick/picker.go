@@ -24,6 +24,11 @@ func FilterExclusions(evs []events.CmdEvent, patterns []string) []events.CmdEven
 	}
 
 	for _, ev := range evs {
+		// Exclusions target noisy commands; notes and markers always stay.
+		if !ev.IsCommand() {
+			filtered = append(filtered, ev)
+			continue
+		}
 		excluded := false
 		// Normalize command for simple common exclusions (check first word)
 		cmdFirstWord := strings.Fields(ev.Cmd)
@@ -103,10 +108,55 @@ func ParseSelection(input string, maxIndex int) ([]int, error) {
 	return selection, nil
 }
 
+// Item is a single step of a selection: the recorded event plus optional
+// annotations used when exporting runbooks. The event is embedded so item
+// JSON stays flat and older selection files still load.
+type Item struct {
+	events.CmdEvent
+	// Section starts a new group of steps with this heading.
+	Section string `json:"section,omitempty"`
+	// Title is a short heading for this step.
+	Title string `json:"title,omitempty"`
+	// Note is free-form prose explaining the step.
+	Note string `json:"note,omitempty"`
+}
+
+// NewItems wraps events as unannotated selection items.
+func NewItems(evs []events.CmdEvent) []Item {
+	items := make([]Item, len(evs))
+	for i, ev := range evs {
+		items[i] = Item{CmdEvent: ev}
+	}
+	return items
+}
+
 type Selection struct {
-	ID        string            `json:"id"`
-	CreatedAt string            `json:"created_at"`
-	Scope     string            `json:"scope"`
-	RepoRoot  string            `json:"repo_root"`
-	Items     []events.CmdEvent `json:"items"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+	Scope       string   `json:"scope"`
+	RepoRoot    string   `json:"repo_root"`
+	Params      []Param  `json:"params,omitempty"`
+	Items       []Item   `json:"items"`
+}
+
+// Param is a named value that exports hoist into a variable. Wherever Value
+// appears in a step's command or directory, the export refers to the
+// variable instead, and Value becomes its default.
+type Param struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
+
+// HasSections reports whether any item in the selection starts a section.
+func (s Selection) HasSections() bool {
+	for _, item := range s.Items {
+		if item.Section != "" {
+			return true
+		}
+	}
+	return false
 }
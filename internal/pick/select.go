@@ -0,0 +1,63 @@
+package pick
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+// SelectIndices returns the events at the given 1-based indices, in order.
+func SelectIndices(evs []events.CmdEvent, indices []int) []events.CmdEvent {
+	var selected []events.CmdEvent
+	for _, idx := range indices {
+		selected = append(selected, evs[idx-1])
+	}
+	return selected
+}
+
+// SelectByIDs returns the events with the given IDs in the order the IDs
+// are listed. Every ID must match an event.
+func SelectByIDs(evs []events.CmdEvent, ids []string) ([]events.CmdEvent, error) {
+	var selected []events.CmdEvent
+	for _, id := range ids {
+		ev, ok := events.FindByID(evs, id)
+		if !ok {
+			return nil, fmt.Errorf("event not found: %s", id)
+		}
+		selected = append(selected, ev)
+	}
+	return selected, nil
+}
+
+// SelectByMatch returns the events whose command matches pattern, in
+// chronological order.
+func SelectByMatch(evs []events.CmdEvent, pattern string) ([]events.CmdEvent, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match pattern: %w", err)
+	}
+
+	var selected []events.CmdEvent
+	for _, ev := range evs {
+		if re.MatchString(ev.Cmd) {
+			selected = append(selected, ev)
+		}
+	}
+	return selected, nil
+}
+
+// SelectLastSuccessful returns the last n commands that exited with status 0,
+// in chronological order. Notes and markers are skipped.
+func SelectLastSuccessful(evs []events.CmdEvent, n int) []events.CmdEvent {
+	var selected []events.CmdEvent
+	for i := len(evs) - 1; i >= 0 && len(selected) < n; i-- {
+		if evs[i].IsCommand() && evs[i].Exit == 0 {
+			selected = append(selected, evs[i])
+		}
+	}
+	for i, j := 0, len(selected)-1; i < j; i, j = i+1, j-1 {
+		selected[i], selected[j] = selected[j], selected[i]
+	}
+	return selected
+}
@@ -0,0 +1,61 @@
+package pick
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+func testEvents() []events.CmdEvent {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []events.CmdEvent{
+		{Ts: ts, Cmd: "go build ./...", Exit: 0},
+		{Ts: ts.Add(1 * time.Second), Cmd: "go test ./...", Exit: 1},
+		{Ts: ts.Add(2 * time.Second), Cmd: "go test -run TestX ./...", Exit: 0},
+		{Ts: ts.Add(3 * time.Second), Cmd: "make lint", Exit: 0},
+	}
+}
+
+func cmdsOf(evs []events.CmdEvent) []string {
+	var out []string
+	for _, ev := range evs {
+		out = append(out, ev.Cmd)
+	}
+	return out
+}
+
+func TestSelectByIDs(t *testing.T) {
+	evs := testEvents()
+	got, err := SelectByIDs(evs, []string{evs[3].ID(), evs[0].ID()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"make lint", "go build ./..."}; !reflect.DeepEqual(cmdsOf(got), want) {
+		t.Errorf("SelectByIDs() = %v, want %v", cmdsOf(got), want)
+	}
+	if _, err := SelectByIDs(evs, []string{"missing"}); err == nil {
+		t.Error("expected error for unknown ID")
+	}
+}
+
+func TestSelectByMatch(t *testing.T) {
+	got, err := SelectByMatch(testEvents(), `^go test`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"go test ./...", "go test -run TestX ./..."}; !reflect.DeepEqual(cmdsOf(got), want) {
+		t.Errorf("SelectByMatch() = %v, want %v", cmdsOf(got), want)
+	}
+	if _, err := SelectByMatch(testEvents(), `(`); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
+
+func TestSelectLastSuccessful(t *testing.T) {
+	got := SelectLastSuccessful(testEvents(), 2)
+	if want := []string{"go test -run TestX ./...", "make lint"}; !reflect.DeepEqual(cmdsOf(got), want) {
+		t.Errorf("SelectLastSuccessful() = %v, want %v", cmdsOf(got), want)
+	}
+}
@@ -0,0 +1,230 @@
+package pick
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var selectionNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._-]*$`)
+var paramNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateName checks that a selection name can be used on the command line.
+// Names must start with a letter so they never look like timestamp IDs.
+func ValidateName(name string) error {
+	if !selectionNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid selection name %q: use letters, digits, '.', '_' or '-', starting with a letter", name)
+	}
+	return nil
+}
+
+// ValidateParamName checks that a parameter name is a valid shell variable.
+func ValidateParamName(name string) error {
+	if !paramNameRegex.MatchString(name) {
+		return fmt.Errorf("invalid parameter name %q: use letters, digits and '_', not starting with a digit", name)
+	}
+	return nil
+}
+
+// SelectionPath returns the file path for a selection ID in dir.
+func SelectionPath(dir, id string) string {
+	return filepath.Join(dir, fmt.Sprintf("selection-%s.json", id))
+}
+
+// LoadSelection reads a selection file.
+func LoadSelection(path string) (Selection, error) {
+	var selection Selection
+	file, err := os.Open(path)
+	if err != nil {
+		return selection, fmt.Errorf("failed to open selection file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&selection); err != nil {
+		return selection, fmt.Errorf("failed to decode selection file: %w", err)
+	}
+	return selection, nil
+}
+
+// WriteSelection replaces the selection file at path. The new content is
+// written to a temporary file first so a failed write never leaves a
+// truncated selection behind.
+func WriteSelection(path string, selection Selection) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".selection-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(selection); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// SaveNewSelection assigns a timestamp ID and creation time to selection and
+// writes it to dir. If another selection was saved in the same second, the
+// ID gets a numeric suffix instead of overwriting it.
+func SaveNewSelection(dir string, selection *Selection) error {
+	if selection.Name != "" {
+		if err := ValidateName(selection.Name); err != nil {
+			return err
+		}
+		if _, err := FindSelectionByName(dir, selection.Name); err == nil {
+			return fmt.Errorf("a selection named %q already exists", selection.Name)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	baseID := now.Format("20060102-150405")
+	selection.ID = baseID
+	selection.CreatedAt = now.Format(time.RFC3339)
+
+	// Later collisions get higher numbers; see selectionFileLess.
+	for n := 2; ; n++ {
+		file, err := os.OpenFile(SelectionPath(dir, selection.ID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			file.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+		selection.ID = fmt.Sprintf("%s_%d", baseID, n)
+	}
+
+	return WriteSelection(SelectionPath(dir, selection.ID), *selection)
+}
+
+// ListSelections returns every selection in dir, oldest first.
+// Unreadable selection files are skipped.
+func ListSelections(dir string) ([]Selection, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasPrefix(f.Name(), "selection-") && strings.HasSuffix(f.Name(), ".json") {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return selectionFileLess(names[i], names[j]) })
+
+	var selections []Selection
+	for _, name := range names {
+		selection, err := LoadSelection(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		selections = append(selections, selection)
+	}
+	return selections, nil
+}
+
+// FindSelectionByName returns the path of the selection with the given name.
+func FindSelectionByName(dir, name string) (string, error) {
+	selections, err := ListSelections(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range selections {
+		if s.Name == name {
+			return SelectionPath(dir, s.ID), nil
+		}
+	}
+	return "", fmt.Errorf("no selection named %q", name)
+}
+
+// ResolveSelection turns a user-supplied reference into a selection file
+// path. The reference may be a file path, a selection ID or a selection
+// name. An empty reference means the most recent selection.
+func ResolveSelection(dir, ref string) (string, error) {
+	if ref == "" {
+		return findMostRecentSelection(dir)
+	}
+	if filepath.IsAbs(ref) || strings.Contains(ref, string(filepath.Separator)) || strings.HasSuffix(ref, ".json") {
+		return ref, nil
+	}
+	if path := SelectionPath(dir, ref); fileExists(path) {
+		return path, nil
+	}
+	if path, err := FindSelectionByName(dir, ref); err == nil {
+		return path, nil
+	}
+	return "", fmt.Errorf("no selection with ID or name %q in %s", ref, dir)
+}
+
+func findMostRecentSelection(dir string) (string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var selections []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasPrefix(f.Name(), "selection-") && strings.HasSuffix(f.Name(), ".json") {
+			selections = append(selections, f.Name())
+		}
+	}
+
+	if len(selections) == 0 {
+		return "", fmt.Errorf("no selections found in %s", dir)
+	}
+
+	// Sort descending (most recent first)
+	sort.Slice(selections, func(i, j int) bool {
+		return selectionFileLess(selections[j], selections[i])
+	})
+
+	return filepath.Join(dir, selections[0]), nil
+}
+
+// selectionFileLess orders selection file names by creation: by the
+// timestamp in the ID, then by the collision number SaveNewSelection appends
+// (none, _2, _3, ... _10), compared as a number.
+func selectionFileLess(a, b string) bool {
+	baseA, nA := selectionFileOrder(a)
+	baseB, nB := selectionFileOrder(b)
+	if baseA != baseB {
+		return baseA < baseB
+	}
+	return nA < nB
+}
+
+// selectionFileOrder splits a selection file name into its ID without the
+// collision number, and that number (1 if there is none).
+func selectionFileOrder(name string) (string, int) {
+	id := strings.TrimSuffix(name, ".json")
+	if i := strings.LastIndexByte(id, '_'); i >= 0 {
+		if n, err := strconv.Atoi(id[i+1:]); err == nil {
+			return id[:i], n
+		}
+	}
+	return id, 1
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
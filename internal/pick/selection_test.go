@@ -0,0 +1,115 @@
+package pick
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+)
+
+func TestSaveAndResolveSelection(t *testing.T) {
+	dir := t.TempDir()
+
+	first := Selection{Name: "setup", Items: NewItems([]events.CmdEvent{{Cmd: "make deps"}})}
+	if err := SaveNewSelection(dir, &first); err != nil {
+		t.Fatal(err)
+	}
+	second := Selection{Items: NewItems([]events.CmdEvent{{Cmd: "make test"}})}
+	if err := SaveNewSelection(dir, &second); err != nil {
+		t.Fatal(err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("selections saved in the same second share ID %s", first.ID)
+	}
+
+	dup := Selection{Name: "setup"}
+	if err := SaveNewSelection(dir, &dup); err == nil {
+		t.Error("expected error for duplicate name")
+	}
+
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"", SelectionPath(dir, second.ID)},
+		{first.ID, SelectionPath(dir, first.ID)},
+		{"setup", SelectionPath(dir, first.ID)},
+		{"/some/file.json", "/some/file.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := ResolveSelection(dir, tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSelection(%q) = %s, want %s", tt.ref, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ResolveSelection(dir, "missing"); err == nil {
+		t.Error("expected error for unknown reference")
+	}
+
+	list, err := ListSelections(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "setup" {
+		t.Errorf("ListSelections() = %+v", list)
+	}
+}
+
+func TestSelectionCollisionOrder(t *testing.T) {
+	dir := t.TempDir()
+	ids := []string{"20240102-030405_10", "20240102-030405", "20240102-030405_2", "20240101-235959_11"}
+	for _, id := range ids {
+		if err := WriteSelection(SelectionPath(dir, id), Selection{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got, err := ResolveSelection(dir, ""); err != nil || got != SelectionPath(dir, "20240102-030405_10") {
+		t.Errorf("most recent = %s, %v; want the _10 collision", got, err)
+	}
+	list, err := ListSelections(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range list {
+		got = append(got, s.ID)
+	}
+	want := []string{"20240101-235959_11", "20240102-030405", "20240102-030405_2", "20240102-030405_10"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListSelections order = %v, want %v", got, want)
+	}
+}
+
+func TestWriteSelectionLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "selection-x.json")
+	if err := WriteSelection(path, Selection{ID: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the selection file, got %d entries", len(entries))
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"setup", "ci.v2", "db_reset-1"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "20240101-120000", "has space", "a/b"} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) should fail", name)
+		}
+	}
+}
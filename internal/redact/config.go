@@ -0,0 +1,255 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ConfigFile is the name of the rules file, looked up in the config
+// directory and in a repository's .cmdsetgo directory.
+const ConfigFile = "redact.json"
+
+var ruleNameRegex = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+var varNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Config is the contents of a rules file.
+type Config struct {
+	// Enable and Disable turn rules on or off by name.
+	Enable  []string `json:"enable,omitempty"`
+	Disable []string `json:"disable,omitempty"`
+	// Rules are additional pattern rules, on unless disabled.
+	Rules []RuleConfig `json:"rules,omitempty"`
+	// Allow lists values that are never redacted, such as documented
+	// example keys.
+	Allow []Allow `json:"allow,omitempty"`
+}
+
+// RuleConfig is a pattern rule declared in a rules file.
+type RuleConfig struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Pattern     string `json:"pattern"`
+	// Group is the submatch holding the secret; 0 means the whole match.
+	Group int `json:"group,omitempty"`
+	// Var names the variable exports use for the secret.
+	Var string `json:"var,omitempty"`
+	// Replacement, if set, replaces the secret instead of ***REDACTED***.
+	// It may refer to the pattern's submatches as $1 or ${name}.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Allow is an allowlist entry matching a secret exactly or by pattern.
+type Allow struct {
+	Value   string `json:"value,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+
+	re *regexp.Regexp
+}
+
+func (a Allow) matches(value string) bool {
+	if a.re != nil {
+		return a.re.MatchString(value)
+	}
+	return a.Value == value
+}
+
+// LoadConfig reads a rules file. A missing file is an empty config.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Merge returns c with other layered on top: rules and allowlists are
+// combined, and a rule other enables or disables overrides c's setting.
+func (c Config) Merge(other Config) Config {
+	return Config{
+		Enable:  append(without(c.Enable, other.Disable), other.Enable...),
+		Disable: append(without(c.Disable, other.Enable), other.Disable...),
+		Rules:   append(append([]RuleConfig(nil), c.Rules...), other.Rules...),
+		Allow:   append(append([]Allow(nil), c.Allow...), other.Allow...),
+	}
+}
+
+// catchAllProbes are values an allow pattern from a repository must not
+// match: the empty string, a password and a random token.
+var catchAllProbes = []string{"", "hunter2", "Zx8Qp2LmV7rT9wKc4NbY6hJ3"}
+
+// Untrusted returns c limited to what a rules file checked into a
+// repository may do, so a cloned repository cannot switch redaction off:
+// it may add rules and enable rules, and allow exact values and patterns
+// that are not catch-alls. Disabled rules, catch-all allow patterns and
+// replacements that can spell out the matched text are dropped, with a
+// warning for each.
+func (c Config) Untrusted() (Config, []string) {
+	var warnings []string
+	for _, name := range c.Disable {
+		warnings = append(warnings, fmt.Sprintf("ignoring disable of rule %q", name))
+	}
+	limited := Config{Enable: c.Enable}
+	for _, rc := range c.Rules {
+		if strings.Contains(rc.Replacement, "$") {
+			warnings = append(warnings, fmt.Sprintf("ignoring replacement %q of rule %s", rc.Replacement, rc.Name))
+			rc.Replacement = ""
+		}
+		limited.Rules = append(limited.Rules, rc)
+	}
+	for _, a := range c.Allow {
+		if a.Pattern != "" && matchesAny(a.Pattern, catchAllProbes) {
+			warnings = append(warnings, fmt.Sprintf("ignoring catch-all allow pattern %q", a.Pattern))
+			continue
+		}
+		limited.Allow = append(limited.Allow, a)
+	}
+	return limited, warnings
+}
+
+// matchesAny reports whether pattern matches one of values. Invalid
+// patterns match nothing.
+func matchesAny(pattern string, values []string) bool {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	for _, v := range values {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns the names in list that are not in remove.
+func without(list, remove []string) []string {
+	var out []string
+	for _, name := range list {
+		if !contains(remove, name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func (c Config) validate() error {
+	_, err := c.compile()
+	return err
+}
+
+// compile checks the config and returns its rules and allowlist ready to
+// use.
+func (c *Config) compile() ([]*Rule, error) {
+	seen := make(map[string]bool)
+	for _, rule := range Builtin {
+		seen[rule.Name] = true
+	}
+
+	var rules []*Rule
+	for i, rc := range c.Rules {
+		if !ruleNameRegex.MatchString(rc.Name) {
+			return nil, fmt.Errorf("rule %d: invalid name %q: use lower-case letters, digits and '-'", i+1, rc.Name)
+		}
+		if seen[rc.Name] {
+			return nil, fmt.Errorf("rule %s: name already in use", rc.Name)
+		}
+		seen[rc.Name] = true
+
+		if rc.Pattern == "" {
+			return nil, fmt.Errorf("rule %s: missing pattern", rc.Name)
+		}
+		re, err := regexp.Compile(rc.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid pattern: %w", rc.Name, err)
+		}
+		if rc.Group < 0 || rc.Group > re.NumSubexp() {
+			return nil, fmt.Errorf("rule %s: group %d does not exist in the pattern", rc.Name, rc.Group)
+		}
+		if rc.Var != "" && !varNameRegex.MatchString(rc.Var) {
+			return nil, fmt.Errorf("rule %s: invalid variable name %q", rc.Name, rc.Var)
+		}
+
+		rule := &Rule{Name: rc.Name, Description: rc.Description, Var: rc.Var, Default: true, re: re, replacement: rc.Replacement}
+		if rc.Group > 0 {
+			rule.valueGroups = []int{rc.Group}
+		}
+		rules = append(rules, rule)
+	}
+
+	for i := range c.Allow {
+		a := &c.Allow[i]
+		switch {
+		case (a.Value == "") == (a.Pattern == ""):
+			return nil, fmt.Errorf("allow entry %d: set exactly one of value or pattern", i+1)
+		case a.Pattern != "":
+			re, err := regexp.Compile(a.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("allow entry %d: invalid pattern: %w", i+1, err)
+			}
+			a.re = re
+		}
+	}
+
+	for _, name := range append(append([]string(nil), c.Enable...), c.Disable...) {
+		if !seen[name] {
+			return nil, fmt.Errorf("unknown redaction rule %q", name)
+		}
+	}
+	return rules, nil
+}
+
+// Redactor builds a redactor from the built-in rules and the config's
+// rules and allowlist. enable and disable override the config's own lists,
+// and customRegexes are added as extra rules.
+func (c Config) Redactor(enable, disable, customRegexes []string) (*Redactor, error) {
+	flags := Config{Enable: enable, Disable: disable}
+	for i, pattern := range customRegexes {
+		flags.Rules = append(flags.Rules, RuleConfig{Name: fmt.Sprintf("custom-%d", i+1), Description: pattern, Pattern: pattern})
+	}
+	c = c.Merge(flags)
+
+	rules, err := c.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	on := make(map[string]bool)
+	for _, rule := range Builtin {
+		on[rule.Name] = rule.Default
+	}
+	for _, rule := range rules {
+		on[rule.Name] = true
+	}
+	for _, name := range c.Enable {
+		on[name] = true
+	}
+	for _, name := range c.Disable {
+		on[name] = false
+	}
+
+	r := &Redactor{Allow: c.Allow}
+	for _, rule := range append(append([]*Rule(nil), Builtin...), rules...) {
+		if on[rule.Name] {
+			r.Rules = append(r.Rules, rule)
+		}
+	}
+	return r, nil
+}
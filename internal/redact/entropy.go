@@ -0,0 +1,80 @@
+package redact
+
+import "math"
+
+// Thresholds for LooksRandom. Random base62/base64 strings of this length
+// score well above 3.7 bits per character, while words, identifiers and
+// hex digests (at most 4 bits, usually without upper case) do not pass the
+// character-class check.
+const (
+	minRandomLength  = 20
+	minRandomEntropy = 3.7
+)
+
+// ShannonEntropy returns the entropy of s in bits per byte.
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	entropy := 0.0
+	n := float64(len(s))
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// LooksRandom reports whether s looks like a generated secret: long, mixing
+// upper case, lower case and digits, and with high Shannon entropy.
+func LooksRandom(s string) bool {
+	if len(s) < minRandomLength {
+		return false
+	}
+	var upper, lower, digit bool
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit && ShannonEntropy(s) >= minRandomEntropy
+}
+
+// Suspects returns random-looking strings in cmd (see LooksRandom) that no
+// rule in r finds or allows, i.e. likely secrets that would slip through.
+func (r *Redactor) Suspects(cmd string) []Secret {
+	var entropy *Rule
+	for _, rule := range Builtin {
+		if rule.Name == "high-entropy" {
+			entropy = rule
+		}
+	}
+	covered := r.FindAll(cmd)
+
+	var suspects []Secret
+	for _, s := range entropy.find(cmd, nil) {
+		overlaps := false
+		for _, c := range covered {
+			if s.Start < c.End && c.Start < s.End {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			suspects = append(suspects, s)
+		}
+	}
+	return suspects
+}
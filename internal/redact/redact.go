@@ -1,66 +1,148 @@
 package redact
 
 import (
-	"regexp"
+	"sort"
 	"strings"
 )
 
-var SecretKeys = []string{
-	"TOKEN", "KEY", "SECRET", "PASSWORD", "PASS", "AUTH", "BEARER",
-	"AWS_SECRET_ACCESS_KEY", "GITHUB_TOKEN",
+// Placeholder replaces secrets in Redactor.Redact's output.
+const Placeholder = "***REDACTED***"
+
+// Secret describes a value found by a redaction rule.
+type Secret struct {
+	// Rule is the name of the rule that found the value.
+	Rule string
+	// Name is the variable or flag the value was assigned to, e.g.
+	// "GITHUB_TOKEN" or "api-key", or the rule's suggested variable name
+	// for bare tokens. It is empty for custom patterns.
+	Name string
+	// Value is the secret itself, without surrounding quotes.
+	Value string
+	// Quote is the quote character enclosing the value in the command
+	// ('"' or '\''), or 0 if it is unquoted.
+	Quote byte
+	// Start and End are the byte offsets in the command of the raw text
+	// that spells Value, including escapes and any quotes that are not
+	// around all of it.
+	Start, End int
+	// Replacement is the rule's replacement text, if it has one.
+	Replacement string
+	// Allowed is set for values on the allowlist; see FindAll.
+	Allowed bool
 }
 
-var SecretFlags = []string{
-	"token", "password", "apikey", "api-key",
+// Redactor applies a set of rules to commands.
+type Redactor struct {
+	Rules []*Rule
+	Allow []Allow
 }
 
-// Redact replaces sensitive values in command strings with ***REDACTED***.
-func Redact(cmd string, customRegexes []string) string {
-	redacted := cmd
+// NewRedactor returns a redactor with the built-in rules that are on by
+// default, adjusted by enable and disable (rule names), followed by the
+// custom patterns. Unknown rule names and invalid patterns are errors.
+func NewRedactor(enable, disable []string, customRegexes []string) (*Redactor, error) {
+	return Config{}.Redactor(enable, disable, customRegexes)
+}
 
-	// 1. Redact KEY=VALUE
-	for _, key := range SecretKeys {
-		// Match KEY=VALUE where VALUE is not already redacted.
-		// Handles shell exports and inline env vars.
-		re := regexp.MustCompile(identRegex(key) + `=[^ \t\n\r"']+`)
-		redacted = re.ReplaceAllStringFunc(redacted, func(match string) string {
-			parts := strings.SplitN(match, "=", 2)
-			return parts[0] + "=***REDACTED***"
-		})
+// Default returns a redactor with the default built-in rules.
+func Default() *Redactor {
+	r, _ := NewRedactor(nil, nil, nil)
+	return r
+}
 
-		// Handle quoted values KEY="VALUE"
-		reQuoted := regexp.MustCompile(identRegex(key) + `="[^"]+"`)
-		redacted = reQuoted.ReplaceAllStringFunc(redacted, func(match string) string {
-			parts := strings.SplitN(match, "=", 2)
-			return parts[0] + "=\"***REDACTED***\""
-		})
+// Find returns the secrets in cmd, ordered by position. Where rules
+// overlap, the match starting first (then the longest) wins. Allowlisted
+// values are left out.
+func (r *Redactor) Find(cmd string) []Secret {
+	var secrets []Secret
+	for _, s := range r.FindAll(cmd) {
+		if !s.Allowed {
+			secrets = append(secrets, s)
+		}
 	}
+	return secrets
+}
 
-	// 2. Redact CLI flags --token <value>
-	for _, flag := range SecretFlags {
-		re := regexp.MustCompile(`--` + flag + `[= ]+[^ \t\n\r"']+`)
-		redacted = re.ReplaceAllStringFunc(redacted, func(match string) string {
-			// Find separator (space or =)
-			sep := " "
-			if strings.Contains(match, "=") {
-				sep = "="
-			}
-			parts := strings.SplitN(match, sep, 2)
-			return parts[0] + sep + "***REDACTED***"
-		})
+// FindAll is like Find but includes allowlisted values, marked Allowed.
+func (r *Redactor) FindAll(cmd string) []Secret {
+	cmds := Tokenize(cmd)
+	var found []Secret
+	for _, rule := range r.Rules {
+		found = append(found, rule.find(cmd, cmds)...)
 	}
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Start != found[j].Start {
+			return found[i].Start < found[j].Start
+		}
+		return found[i].End > found[j].End
+	})
 
-	// 3. Apply custom regexes
-	for _, pattern := range customRegexes {
-		if re, err := regexp.Compile(pattern); err == nil {
-			redacted = re.ReplaceAllString(redacted, "***REDACTED***")
+	var kept []Secret
+	end := -1
+	for _, s := range found {
+		if s.Start < end {
+			continue
 		}
+		s.Quote = quoteAt(cmd, s.Start)
+		for _, a := range r.Allow {
+			if a.matches(s.Value) {
+				s.Allowed = true
+			}
+		}
+		kept = append(kept, s)
+		end = s.End
 	}
+	return kept
+}
 
-	return redacted
+// RedactFunc replaces each secret in cmd with the result of replace.
+func (r *Redactor) RedactFunc(cmd string, replace func(Secret) string) string {
+	secrets := r.Find(cmd)
+	if len(secrets) == 0 {
+		return cmd
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range secrets {
+		b.WriteString(cmd[last:s.Start])
+		b.WriteString(replace(s))
+		last = s.End
+	}
+	b.WriteString(cmd[last:])
+	return b.String()
+}
+
+// Redact replaces each secret in cmd with its rule's replacement, or
+// ***REDACTED*** if the rule has none.
+func (r *Redactor) Redact(cmd string) string {
+	return r.RedactFunc(cmd, func(s Secret) string {
+		if s.Replacement != "" {
+			return s.Replacement
+		}
+		return Placeholder
+	})
 }
 
-func identRegex(key string) string {
-	// Case insensitive match for the key, but must be exactly the key or end with it (e.g. GITHUB_TOKEN)
-	return `(?i:[A-Z0-9_]*` + key + `)`
+// quoteAt returns the quote character in effect at byte offset pos of a
+// shell command, or 0 outside quotes.
+func quoteAt(cmd string, pos int) byte {
+	var quote byte
+	for i := 0; i < pos && i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			}
+		case c == '\\':
+			i++
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		}
+	}
+	return quote
 }
@@ -1,6 +1,9 @@
 package redact
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestRedact(t *testing.T) {
 	tests := []struct {
@@ -49,9 +52,55 @@ func TestRedact(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := Redact(tt.cmd, tt.patterns); got != tt.want {
+			r, err := NewRedactor(nil, nil, tt.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Redact(tt.cmd); got != tt.want {
 				t.Errorf("Redact() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestRedactFunc(t *testing.T) {
+	var got []Secret
+	cmd := `GITHUB_TOKEN=abc DB_PASS="x y" mycli --api-key=k1 && echo hunter2`
+	r, err := NewRedactor(nil, nil, []string{`hunter2`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := r.RedactFunc(cmd, func(s Secret) string {
+		got = append(got, s)
+		return "<" + s.Name + ">"
+	})
+
+	if want := `GITHUB_TOKEN=<GITHUB_TOKEN> DB_PASS="<DB_PASS>" mycli --api-key=<api-key> && echo <>`; out != want {
+		t.Errorf("RedactFunc() = %q, want %q", out, want)
+	}
+	want := []Secret{
+		{Rule: "env-assignment", Name: "GITHUB_TOKEN", Value: "abc"},
+		{Rule: "env-assignment", Name: "DB_PASS", Value: "x y", Quote: '"'},
+		{Rule: "secret-flag", Name: "api-key", Value: "k1"},
+		{Rule: "custom-1", Value: "hunter2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("secrets = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		want[i].Start = strings.Index(cmd, want[i].Value)
+		want[i].End = want[i].Start + len(want[i].Value)
+		if got[i] != want[i] {
+			t.Errorf("secret %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNewRedactorErrors(t *testing.T) {
+	if _, err := NewRedactor([]string{"no-such-rule"}, nil, nil); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+	if _, err := NewRedactor(nil, nil, []string{"("}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
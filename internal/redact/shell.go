@@ -0,0 +1,166 @@
+package redact
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Token is one shell word after quote removal, with enough position
+// information to map any part of it back to the original command.
+type Token struct {
+	Value      string
+	Start, End int
+	// pos[i] is the offset in the command of Value[i].
+	pos []int
+	// cmd is the command the token was read from.
+	cmd string
+}
+
+// span returns a Secret for Value[i:j]. The span covers the raw characters
+// that produced those bytes, so escapes inside it are replaced as well, and
+// is chosen so that the command is quoted the same way at both ends: a
+// value that runs to the end of the token takes in the quotes and escapes
+// around it unless it lies inside a single pair of quotes.
+func (t Token) span(rule, name string, i, j int) Secret {
+	end := t.pos[j-1] + 1
+	if j == len(t.Value) {
+		end = t.End
+	}
+	lead := t.Start
+	if i > 0 {
+		lead = t.pos[i-1] + 1
+	}
+	starts := []int{lead}
+	if !strings.Contains(t.cmd[lead:t.pos[i]], `\`) {
+		starts = []int{t.pos[i], lead}
+	}
+	for _, start := range starts {
+		quote := quoteAt(t.cmd, start)
+		e := end
+		if j == len(t.Value) && quote != 0 && t.cmd[e-1] == quote && quoteAt(t.cmd, e-1) == quote {
+			e-- // the closing quote of the value's own quotes
+		}
+		if quoteAt(t.cmd, e) == quote {
+			return Secret{Rule: rule, Name: name, Value: t.Value[i:j], Start: start, End: e}
+		}
+	}
+	// The value starts inside quotes opened before it and runs past their
+	// end, so replace from the opening quote.
+	start := t.pos[i]
+	for start > t.Start && quoteAt(t.cmd, start) != 0 {
+		start--
+	}
+	return Secret{Rule: rule, Name: name, Value: t.Value[i:j], Start: start, End: end}
+}
+
+// Tokenize splits a shell command line into simple commands and those into
+// words. It understands single and double quotes, backslash escapes,
+// comments and the ; & | && || ( ) and newline separators. Expansions are
+// kept as literal text.
+func Tokenize(cmd string) [][]Token {
+	var (
+		cmds    [][]Token
+		words   []Token
+		cur     *Token
+		quote   byte
+		comment bool
+	)
+	endWord := func() {
+		if cur != nil {
+			words = append(words, *cur)
+			cur = nil
+		}
+	}
+	endCmd := func() {
+		endWord()
+		if len(words) > 0 {
+			cmds = append(cmds, words)
+			words = nil
+		}
+	}
+	add := func(c byte, at int) {
+		if cur == nil {
+			cur = &Token{Start: at, cmd: cmd}
+		}
+		cur.Value += string(c)
+		cur.pos = append(cur.pos, at)
+		cur.End = at + 1
+	}
+
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case comment:
+			if c == '\n' {
+				comment = false
+				endCmd()
+			}
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+				cur.End = i + 1
+			} else {
+				add(c, i)
+			}
+		case quote == '"':
+			switch {
+			case c == '"':
+				quote = 0
+				cur.End = i + 1
+			case c == '\\' && i+1 < len(cmd) && strings.IndexByte("$`\"\\\n", cmd[i+1]) >= 0:
+				i++
+				add(cmd[i], i)
+			default:
+				add(c, i)
+			}
+		case c == '\\' && i+1 < len(cmd):
+			if cur == nil && cmd[i+1] != '\n' {
+				cur = &Token{Start: i, cmd: cmd}
+			}
+			i++
+			if cmd[i] != '\n' {
+				add(cmd[i], i)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			if cur == nil {
+				cur = &Token{Start: i, cmd: cmd}
+			}
+			cur.End = i + 1
+		case c == ' ' || c == '\t':
+			endWord()
+		case c == '\n' || c == ';' || c == '&' || c == '|' || c == '(' || c == ')':
+			endCmd()
+		case c == '#' && cur == nil:
+			comment = true
+		default:
+			add(c, i)
+		}
+	}
+	endCmd()
+	return cmds
+}
+
+// commandWrappers run the command that follows them.
+var commandWrappers = map[string]bool{
+	"sudo": true, "env": true, "time": true, "exec": true, "nohup": true, "command": true,
+}
+
+// toolArgs returns the tool name of a simple command and its words starting
+// at the tool, skipping leading VAR=value assignments and wrappers such as
+// sudo and env.
+func toolArgs(words []Token) (string, []Token) {
+	wrapped := false
+	for i, w := range words {
+		switch name := filepath.Base(w.Value); {
+		case wrapped && strings.HasPrefix(w.Value, "-"):
+			// Wrapper options such as sudo -E or env -i.
+		case strings.Contains(w.Value, "=") && !strings.HasPrefix(w.Value, "-"):
+		case commandWrappers[name]:
+			wrapped = true
+		default:
+			return name, words[i:]
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,76 @@
+package redact
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want [][]string
+	}{
+		{"echo a  b", [][]string{{"echo", "a", "b"}}},
+		{`echo "a b" 'c d' e\ f`, [][]string{{"echo", "a b", "c d", "e f"}}},
+		{`echo "a\"b" 'a\b' ""`, [][]string{{"echo", `a"b`, `a\b`, ""}}},
+		{"a && b || c; d | e & f", [][]string{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}, {"f"}}},
+		{"a # comment\nb", [][]string{{"a"}, {"b"}}},
+		{"x=1 a#b", [][]string{{"x=1", "a#b"}}},
+	}
+	for _, tt := range tests {
+		var got [][]string
+		for _, words := range Tokenize(tt.cmd) {
+			var values []string
+			for _, w := range words {
+				values = append(values, w.Value)
+			}
+			got = append(got, values)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestTokenSpan(t *testing.T) {
+	cmd := `run --token="a\"b" next`
+	words := Tokenize(cmd)[0]
+	s := words[1].span("r", "token", len("--token="), len(words[1].Value))
+	if s.Value != `a"b` || cmd[s.Start:s.End] != `a\"b` {
+		t.Errorf("span = %q (raw %q)", s.Value, cmd[s.Start:s.End])
+	}
+}
+
+func TestToolArgs(t *testing.T) {
+	tests := map[string]string{
+		"mysql -p x":                    "mysql",
+		"FOO=1 sudo -E /usr/bin/mysql":  "mysql",
+		"env -i PATH=/bin docker login": "docker",
+		"A=1":                           "",
+	}
+	for cmd, want := range tests {
+		if got, _ := toolArgs(Tokenize(cmd)[0]); got != want {
+			t.Errorf("toolArgs(%q) = %q, want %q", cmd, got, want)
+		}
+	}
+}
+
+func TestRedactQuotedAndEscaped(t *testing.T) {
+	tests := []struct{ cmd, want string }{
+		{`mycli --token "abc"`, `mycli --token "***REDACTED***"`},
+		{`mycli --token=ab\ c next`, `mycli --token=***REDACTED*** next`},
+		{`curl -H "Authorization: Bearer xyz" -u bob:pw https://u:p@h`, `curl -H "Authorization: Bearer ***REDACTED***" -u bob:***REDACTED*** https://u:***REDACTED***@h`},
+		{`mycli --token=ab"cd" next`, `mycli --token=***REDACTED*** next`},
+		{`mycli --token="ab"'cd'`, `mycli --token=***REDACTED***`},
+		{`mycli --token \"abc next`, `mycli --token ***REDACTED*** next`},
+		{`mycli --token=\'abc`, `mycli --token=***REDACTED***`},
+		{`mycli --token 'a'b`, `mycli --token ***REDACTED***`},
+		{`API_KEY='a'"b" ./run`, `API_KEY=***REDACTED*** ./run`},
+		{`mycli "--token=ab"cd`, `mycli ***REDACTED***`},
+	}
+	for _, tt := range tests {
+		if got := Default().Redact(tt.cmd); got != tt.want {
+			t.Errorf("Redact(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RunsDir returns the directory run logs are stored in under stateDir.
+func RunsDir(stateDir string) string {
+	return filepath.Join(stateDir, "runs")
+}
+
+// LogPath returns the path of the run log with the given ID in dir.
+func LogPath(dir, id string) string {
+	return filepath.Join(dir, fmt.Sprintf("run-%s.json", id))
+}
+
+// SaveLog writes the run log to dir as run-<id>.json and returns its path.
+// Like selections, a log saved in the same second gets a numeric suffix.
+func SaveLog(dir string, log *Log) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	baseID := log.ID
+	for n := 2; ; n++ {
+		path := LogPath(dir, log.ID)
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			defer file.Close()
+			encoder := json.NewEncoder(file)
+			encoder.SetIndent("", "  ")
+			return path, encoder.Encode(log)
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+		log.ID = fmt.Sprintf("%s_%d", baseID, n)
+	}
+}
+
+// LoadLog reads a run log file.
+func LoadLog(path string) (Log, error) {
+	var log Log
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return log, err
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return log, fmt.Errorf("failed to decode run log %s: %w", path, err)
+	}
+	return log, nil
+}
+
+// WriteLog replaces the run log file at path, writing a temporary file
+// first so a failed write never leaves a truncated log behind.
+func WriteLog(path string, log Log) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".run-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ListLogs returns the run logs in dir, oldest first. Unreadable files are
+// skipped.
+func ListLogs(dir string) ([]Log, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		if !f.IsDir() && strings.HasPrefix(f.Name(), "run-") && strings.HasSuffix(f.Name(), ".json") {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var logs []Log
+	for _, name := range names {
+		log, err := LoadLog(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
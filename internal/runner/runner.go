@@ -0,0 +1,246 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// Step statuses recorded in the run log.
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+	StatusDryRun  = "dry-run"
+)
+
+// Decision is the answer to a per-step confirmation prompt.
+type Decision int
+
+const (
+	DecisionRun Decision = iota
+	DecisionSkip
+	DecisionAbort
+)
+
+// Options controls how a selection is replayed.
+type Options struct {
+	// Root relocates steps recorded under the selection's repo root to this
+	// directory, and rewrites the repo root where commands mention it.
+	// Empty means run in the recorded directories.
+	Root string
+	// From is the 1-based step to start at; earlier steps are skipped.
+	From int
+	// DryRun prints what would run without executing anything.
+	DryRun bool
+	// KeepGoing continues after a failing step instead of stopping.
+	KeepGoing bool
+	// Shell runs each command as `<Shell> -c <cmd>`. Defaults to bash.
+	Shell string
+	// Env is the environment for every step. Nil inherits the current one.
+	Env []string
+	// Confirm, if set, is asked before each step is run.
+	Confirm func(step int, item pick.Item, cwd string) (Decision, error)
+
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// StepResult records what happened to one selection item. The embedded
+// event describes the command as it was executed by this run.
+type StepResult struct {
+	Step   int    `json:"step"`
+	Status string `json:"status"`
+	events.CmdEvent
+}
+
+// Log is the record of one replay, saved under the state directory.
+type Log struct {
+	ID            string       `json:"id"`
+	SelectionID   string       `json:"selection_id"`
+	SelectionName string       `json:"selection_name,omitempty"`
+	Root          string       `json:"root,omitempty"`
+	StartedAt     time.Time    `json:"started_at"`
+	FinishedAt    time.Time    `json:"finished_at"`
+	Steps         []StepResult `json:"steps"`
+}
+
+// Failed returns the first failed step, if any.
+func (l Log) Failed() (StepResult, bool) {
+	for _, s := range l.Steps {
+		if s.Status == StatusFailed {
+			return s, true
+		}
+	}
+	return StepResult{}, false
+}
+
+// Relocate maps cwd from under fromRoot to the same relative path under
+// toRoot. Paths outside fromRoot, or an empty toRoot, are returned unchanged.
+func Relocate(cwd, fromRoot, toRoot string) string {
+	if toRoot == "" || fromRoot == "" {
+		return cwd
+	}
+	rel, err := filepath.Rel(fromRoot, cwd)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return cwd
+	}
+	return filepath.Join(toRoot, rel)
+}
+
+// RelocateCommand replaces fromRoot with toRoot where it appears in cmd as
+// a whole path or the start of one, so that "rm -rf /repo/build" run under
+// toRoot does not touch /repo. An empty toRoot leaves cmd unchanged.
+func RelocateCommand(cmd, fromRoot, toRoot string) string {
+	fromRoot = strings.TrimRight(fromRoot, "/")
+	if toRoot == "" || fromRoot == "" {
+		return cmd
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(cmd, fromRoot)
+		if i < 0 {
+			b.WriteString(cmd)
+			return b.String()
+		}
+		j := i + len(fromRoot)
+		if (i == 0 || !isPathByte(cmd[i-1])) && (j == len(cmd) || cmd[j] == '/' || !isPathByte(cmd[j])) {
+			b.WriteString(cmd[:i] + toRoot)
+		} else {
+			b.WriteString(cmd[:j])
+		}
+		cmd = cmd[j:]
+	}
+}
+
+// isPathByte reports whether c commonly appears in a path, so a root next
+// to it is part of a longer name.
+func isPathByte(c byte) bool {
+	return c == '/' || c == '.' || c == '_' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// Run replays the selection's commands in order and returns the run log.
+// Notes and markers are printed but not executed. The returned log is
+// complete even when a step fails; err is only set for problems running
+// the replay itself (e.g. an aborted confirmation).
+func Run(selection pick.Selection, opts Options) (log Log, err error) {
+	if opts.Shell == "" {
+		opts.Shell = "bash"
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+
+	log = Log{
+		ID:            time.Now().Format("20060102-150405"),
+		SelectionID:   selection.ID,
+		SelectionName: selection.Name,
+		Root:          opts.Root,
+		StartedAt:     time.Now(),
+	}
+	defer func() { log.FinishedAt = time.Now() }()
+
+	host, _ := os.Hostname()
+	failed := false
+	for i, item := range selection.Items {
+		step := i + 1
+		cwd := Relocate(item.Cwd, selection.RepoRoot, opts.Root)
+		if item.IsCommand() {
+			item.Cmd = RelocateCommand(item.Cmd, selection.RepoRoot, opts.Root)
+		}
+		result := StepResult{
+			Step: step,
+			CmdEvent: events.CmdEvent{
+				Type:  item.Type,
+				Shell: opts.Shell,
+				Host:  host,
+				User:  os.Getenv("USER"),
+				Cwd:   cwd,
+				Cmd:   item.Cmd,
+			},
+		}
+
+		if !item.IsCommand() {
+			fmt.Fprintf(opts.Stdout, "%s\n", item.Label())
+			continue
+		}
+
+		if step < opts.From || failed {
+			result.Status = StatusSkipped
+			log.Steps = append(log.Steps, result)
+			continue
+		}
+
+		fmt.Fprintf(opts.Stdout, "==> [%d/%d] (%s) %s\n", step, len(selection.Items), cwd, item.Cmd)
+
+		if opts.DryRun {
+			result.Status = StatusDryRun
+			log.Steps = append(log.Steps, result)
+			continue
+		}
+
+		if opts.Confirm != nil {
+			decision, err := opts.Confirm(step, item, cwd)
+			if err != nil {
+				return log, err
+			}
+			if decision == DecisionAbort {
+				return log, fmt.Errorf("run aborted at step %d", step)
+			}
+			if decision == DecisionSkip {
+				result.Status = StatusSkipped
+				log.Steps = append(log.Steps, result)
+				continue
+			}
+		}
+
+		result.CmdEvent = execute(result.CmdEvent, opts)
+		if result.Exit == 0 {
+			result.Status = StatusOK
+		} else {
+			result.Status = StatusFailed
+			fmt.Fprintf(opts.Stderr, "==> step %d failed with exit code %d\n", step, result.Exit)
+			failed = !opts.KeepGoing
+		}
+		log.Steps = append(log.Steps, result)
+	}
+
+	return log, nil
+}
+
+// execute runs ev.Cmd in ev.Cwd and fills in the timing and exit code.
+func execute(ev events.CmdEvent, opts Options) events.CmdEvent {
+	cmd := exec.Command(opts.Shell, "-c", ev.Cmd)
+	cmd.Dir = ev.Cwd
+	cmd.Env = opts.Env
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+
+	ev.Ts = time.Now()
+	err := cmd.Run()
+	ev.DurationMs = time.Since(ev.Ts).Milliseconds()
+
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			ev.Exit = exitErr.ExitCode()
+		} else {
+			// The command never started (missing directory, shell, ...).
+			fmt.Fprintf(opts.Stderr, "==> %v\n", err)
+			ev.Exit = 127
+		}
+	}
+	return ev
+}
@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+func TestRelocate(t *testing.T) {
+	tests := []struct {
+		cwd, from, to, want string
+	}{
+		{"/repo/src", "/repo", "/tmp/copy", "/tmp/copy/src"},
+		{"/repo", "/repo", "/tmp/copy", "/tmp/copy"},
+		{"/other", "/repo", "/tmp/copy", "/other"},
+		{"/repository", "/repo", "/tmp/copy", "/repository"},
+		{"/repo/src", "/repo", "", "/repo/src"},
+	}
+	for _, tt := range tests {
+		if got := Relocate(tt.cwd, tt.from, tt.to); got != tt.want {
+			t.Errorf("Relocate(%q, %q, %q) = %q, want %q", tt.cwd, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestRelocateCommand(t *testing.T) {
+	tests := []struct {
+		cmd, want string
+	}{
+		{"rm -rf /repo/build", "rm -rf /tmp/copy/build"},
+		{`cd "/repo" && ls /repo;`, `cd "/tmp/copy" && ls /tmp/copy;`},
+		{"cp /repository/x /mnt/repo/y /repo.bak", "cp /repository/x /mnt/repo/y /repo.bak"},
+		{"echo --dir=/repo/", "echo --dir=/tmp/copy/"},
+	}
+	for _, tt := range tests {
+		if got := RelocateCommand(tt.cmd, "/repo", "/tmp/copy"); got != tt.want {
+			t.Errorf("RelocateCommand(%q) = %q, want %q", tt.cmd, got, tt.want)
+		}
+	}
+	if got := RelocateCommand("rm -rf /repo/build", "/repo", ""); got != "rm -rf /repo/build" {
+		t.Errorf("RelocateCommand without a root = %q", got)
+	}
+}
+
+func TestRunRelocatesCommands(t *testing.T) {
+	orig, root := t.TempDir(), t.TempDir()
+	sel := testSelection(orig, "touch "+filepath.Join(orig, "made"))
+
+	log, err := Run(sel, Options{Root: root, Stdout: io.Discard, Stderr: io.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "made")); err != nil {
+		t.Errorf("step did not run under the root: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(orig, "made")); err == nil {
+		t.Error("step touched the original repo root")
+	}
+	if want := "touch " + filepath.Join(root, "made"); log.Steps[0].Cmd != want {
+		t.Errorf("logged command = %q, want %q", log.Steps[0].Cmd, want)
+	}
+}
+
+func statuses(log Log) []string {
+	var out []string
+	for _, s := range log.Steps {
+		out = append(out, s.Status)
+	}
+	return out
+}
+
+func testSelection(dir string, cmds ...string) pick.Selection {
+	var evs []events.CmdEvent
+	for _, c := range cmds {
+		evs = append(evs, events.CmdEvent{Type: "cmd", Cwd: dir, Cmd: c})
+	}
+	return pick.Selection{ID: "test", RepoRoot: dir, Items: pick.NewItems(evs)}
+}
+
+func TestRunStopsOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	sel := testSelection(dir, "echo one > out.txt", "exit 3", "echo never >> out.txt")
+	sel.Items = append(sel.Items[:1], append([]pick.Item{{CmdEvent: events.CmdEvent{Type: "note", Cmd: "hello"}}}, sel.Items[1:]...)...)
+
+	var out bytes.Buffer
+	log, err := Run(sel, Options{Stdout: &out, Stderr: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{StatusOK, StatusFailed, StatusSkipped}; !reflect.DeepEqual(statuses(log), want) {
+		t.Errorf("statuses = %v, want %v", statuses(log), want)
+	}
+	if failed, ok := log.Failed(); !ok || failed.Step != 3 || failed.Exit != 3 {
+		t.Errorf("Failed() = %+v, %v", failed, ok)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if string(data) != "one\n" {
+		t.Errorf("out.txt = %q", data)
+	}
+}
+
+func TestRunFromKeepGoingAndDryRun(t *testing.T) {
+	dir := t.TempDir()
+	sel := testSelection(dir, "exit 1", "false", "true")
+
+	var out bytes.Buffer
+	log, err := Run(sel, Options{From: 2, KeepGoing: true, Stdout: &out, Stderr: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{StatusSkipped, StatusFailed, StatusOK}; !reflect.DeepEqual(statuses(log), want) {
+		t.Errorf("statuses = %v, want %v", statuses(log), want)
+	}
+
+	log, err = Run(testSelection(dir, "touch created"), Options{DryRun: true, Stdout: &out, Stderr: &out})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "created")); !os.IsNotExist(err) || log.Steps[0].Status != StatusDryRun {
+		t.Errorf("dry run executed the command")
+	}
+}
+
+func TestRunConfirm(t *testing.T) {
+	dir := t.TempDir()
+	sel := testSelection(dir, "true", "true", "true")
+
+	var out bytes.Buffer
+	answers := []Decision{DecisionRun, DecisionSkip, DecisionAbort}
+	log, err := Run(sel, Options{
+		Stdout: &out,
+		Stderr: &out,
+		Confirm: func(step int, item pick.Item, cwd string) (Decision, error) {
+			return answers[step-1], nil
+		},
+	})
+	if err == nil {
+		t.Fatal("expected abort error")
+	}
+	if want := []string{StatusOK, StatusSkipped}; !reflect.DeepEqual(statuses(log), want) {
+		t.Errorf("statuses = %v, want %v", statuses(log), want)
+	}
+}
+
+func TestRunLogs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "runs")
+	first := Log{ID: "20240102-030405", Steps: []StepResult{{Step: 1, Status: StatusOK, CmdEvent: events.CmdEvent{Cmd: "make"}}}}
+	second := first
+	path, err := SaveLog(dir, &first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SaveLog(dir, &second); err != nil {
+		t.Fatal(err)
+	}
+	if path != LogPath(dir, first.ID) || second.ID != first.ID+"_2" {
+		t.Errorf("saved %s and %s at %s", first.ID, second.ID, path)
+	}
+
+	first.Steps = nil
+	if err := WriteLog(path, first); err != nil {
+		t.Fatal(err)
+	}
+	logs, err := ListLogs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 || logs[0].ID != first.ID || len(logs[0].Steps) != 0 || len(logs[1].Steps) != 1 {
+		t.Errorf("ListLogs() = %+v", logs)
+	}
+}
@@ -0,0 +1,170 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// sandboxEnv lists the variables passed through to sandboxed steps. Anything
+// else (tokens, cloud credentials, agent sockets) is dropped.
+var sandboxEnv = []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "TERM", "LANG", "LC_ALL", "LC_CTYPE", "TZ"}
+
+// Sandbox is a throwaway copy of a repository for replaying a selection
+// without touching the real checkout.
+type Sandbox struct {
+	// Dir is the temporary directory holding everything the sandbox created.
+	Dir string
+	// Root is the copy of the selection's repo root inside Dir.
+	Root string
+	// Worktree is set when Root is a git worktree of the original repo
+	// rather than a plain copy.
+	Worktree bool
+
+	gitDir      string
+	worktreeDir string
+}
+
+// NewSandbox copies repoRoot into a new temporary directory. If repoRoot is
+// inside a git repository, a detached worktree of HEAD is created instead,
+// so uncommitted changes are not carried over; otherwise the files are
+// copied as they are.
+func NewSandbox(repoRoot string) (*Sandbox, error) {
+	if repoRoot == "" {
+		return nil, fmt.Errorf("selection has no repo root to copy")
+	}
+	dir, err := os.MkdirTemp("", "cmdsetgo-sandbox-")
+	if err != nil {
+		return nil, err
+	}
+	sb := &Sandbox{Dir: dir}
+
+	if top, ok := gitTopLevel(repoRoot); ok {
+		worktree := filepath.Join(dir, filepath.Base(top))
+		// This fails for a repository without commits; fall back to a copy.
+		if err := exec.Command("git", "-C", top, "worktree", "add", "--detach", worktree, "HEAD").Run(); err == nil {
+			rel, _ := filepath.Rel(top, repoRoot)
+			sb.Root = filepath.Join(worktree, rel)
+			sb.Worktree = true
+			sb.gitDir = top
+			sb.worktreeDir = worktree
+			return sb, nil
+		}
+	}
+
+	sb.Root = filepath.Join(dir, filepath.Base(repoRoot))
+	if err := copyTree(repoRoot, sb.Root); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to copy %s into sandbox: %w", repoRoot, err)
+	}
+	return sb, nil
+}
+
+// Env returns a scrubbed copy of environ for sandboxed steps, keeping only
+// the allowlisted variables plus any named in extra. TMPDIR points inside
+// the sandbox.
+func (sb *Sandbox) Env(environ []string, extra []string) ([]string, error) {
+	tmp := filepath.Join(sb.Dir, "tmp")
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return nil, err
+	}
+
+	keep := make(map[string]bool)
+	for _, name := range append(sandboxEnv, extra...) {
+		keep[name] = true
+	}
+	var env []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if keep[name] {
+			env = append(env, kv)
+		}
+	}
+	return append(env, "TMPDIR="+tmp), nil
+}
+
+// Cleanup removes the sandbox, unregistering the worktree if one was made.
+func (sb *Sandbox) Cleanup() error {
+	if sb.Worktree {
+		if out, err := exec.Command("git", "-C", sb.gitDir, "worktree", "remove", "--force", sb.worktreeDir).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to remove worktree %s: %s", sb.worktreeDir, strings.TrimSpace(string(out)))
+		}
+	}
+	return os.RemoveAll(sb.Dir)
+}
+
+func gitTopLevel(dir string) (string, bool) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(out)), true
+}
+
+// copyTree copies the directory src to dst, preserving file modes and
+// symlinks. Other special files are skipped.
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		}
+		return nil
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// StepsOutside returns the numbers of command steps whose directory is not
+// under the selection's repo root. A sandbox cannot contain those steps.
+func StepsOutside(selection pick.Selection) []int {
+	var steps []int
+	for i, item := range selection.Items {
+		if !item.IsCommand() {
+			continue
+		}
+		rel, err := filepath.Rel(selection.RepoRoot, item.Cwd)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			steps = append(steps, i+1)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSandboxCopiesPlainDirectory(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repo, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "src", "main.sh"), []byte("echo hi\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("src/main.sh", filepath.Join(repo, "run")); err != nil {
+		t.Fatal(err)
+	}
+
+	sb, err := NewSandbox(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sb.Worktree {
+		t.Fatal("expected a plain copy outside a git repository")
+	}
+
+	info, err := os.Stat(filepath.Join(sb.Root, "src", "main.sh"))
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("copied file = %v, %v", info, err)
+	}
+	if link, err := os.Readlink(filepath.Join(sb.Root, "run")); err != nil || link != "src/main.sh" {
+		t.Errorf("copied symlink = %q, %v", link, err)
+	}
+
+	if err := sb.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(sb.Dir); !os.IsNotExist(err) {
+		t.Errorf("sandbox dir still exists after Cleanup: %v", err)
+	}
+}
+
+func TestSandboxUsesGitWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	if err := os.MkdirAll(filepath.Join(repo, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "sub", "committed"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	if err := os.WriteFile(filepath.Join(repo, "sub", "untracked"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sb, err := NewSandbox(filepath.Join(repo, "sub"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sb.Worktree {
+		t.Fatal("expected a git worktree")
+	}
+	if filepath.Base(sb.Root) != "sub" {
+		t.Errorf("Root = %s, want the sub directory of the worktree", sb.Root)
+	}
+	if _, err := os.Stat(filepath.Join(sb.Root, "committed")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filepath.Join(sb.Root, "untracked")); !os.IsNotExist(err) {
+		t.Error("untracked file should not be in the worktree")
+	}
+
+	if err := sb.Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("git", "-C", repo, "worktree", "list", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), sb.Dir) {
+		t.Errorf("worktree still registered:\n%s", out)
+	}
+}
+
+func TestSandboxEnv(t *testing.T) {
+	sb := &Sandbox{Dir: t.TempDir()}
+	env, err := sb.Env([]string{"PATH=/bin", "GITHUB_TOKEN=secret", "HOME=/home/me", "GOFLAGS=-mod=mod"}, []string{"GOFLAGS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"PATH=/bin", "HOME=/home/me", "GOFLAGS=-mod=mod", "TMPDIR=" + filepath.Join(sb.Dir, "tmp")}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("Env = %v, want %v", env, want)
+	}
+}
+
+func TestStepsOutside(t *testing.T) {
+	sel := testSelection("/repo", "make", "make test")
+	sel.Items[1].Cwd = "/elsewhere"
+	if got := StepsOutside(sel); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("StepsOutside = %v, want [2]", got)
+	}
+}
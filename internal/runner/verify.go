@@ -0,0 +1,232 @@
+package runner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+// Tolerance bounds how far a replayed step's duration may drift from the
+// recorded one. A step passes if it is within Factor times the recorded
+// duration in either direction, or within Slack of it. A zero Factor
+// disables duration checks.
+type Tolerance struct {
+	Factor float64
+	Slack  time.Duration
+}
+
+// Validate rejects factors that would fail every duration check: a step
+// can never be within less than its own recorded duration.
+func (t Tolerance) Validate() error {
+	if t.Factor != 0 && t.Factor < 1 {
+		return fmt.Errorf("tolerance factor %g must be at least 1 (or 0 to disable)", t.Factor)
+	}
+	if t.Slack < 0 {
+		return fmt.Errorf("tolerance slack %s must not be negative", t.Slack)
+	}
+	return nil
+}
+
+// Check is the verification result for one step.
+type Check struct {
+	Step       int    `json:"step"`
+	Cmd        string `json:"cmd"`
+	Status     string `json:"status"`
+	WantExit   int    `json:"want_exit"`
+	GotExit    int    `json:"got_exit"`
+	WantMs     int64  `json:"want_duration_ms"`
+	GotMs      int64  `json:"got_duration_ms"`
+	ExitOK     bool   `json:"exit_ok"`
+	DurationOK bool   `json:"duration_ok"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Passed reports whether the step matched its recording. Skipped steps
+// count as neither passed nor failed.
+func (c Check) Passed() bool {
+	return c.Status != StatusSkipped && c.ExitOK && c.DurationOK
+}
+
+// Report is the outcome of verifying a run against its selection.
+type Report struct {
+	Selection string
+	Duration  time.Duration
+	Checks    []Check
+}
+
+// Failures returns the number of steps that did not match their recording.
+func (r Report) Failures() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status != StatusSkipped && !c.Passed() {
+			n++
+		}
+	}
+	return n
+}
+
+// Verify compares each executed step in log with the event recorded in the
+// selection. A step passes when its exit code is the same and, if the
+// recording has a duration, its duration is within tol.
+func Verify(selection pick.Selection, log Log, tol Tolerance) Report {
+	name := selection.ID
+	if selection.Name != "" {
+		name = selection.Name
+	}
+	report := Report{Selection: name, Duration: log.FinishedAt.Sub(log.StartedAt)}
+
+	for _, result := range log.Steps {
+		orig := selection.Items[result.Step-1]
+		c := Check{
+			Step:       result.Step,
+			Cmd:        orig.Cmd,
+			Status:     result.Status,
+			WantExit:   orig.Exit,
+			GotExit:    result.Exit,
+			WantMs:     orig.DurationMs,
+			GotMs:      result.DurationMs,
+			ExitOK:     true,
+			DurationOK: true,
+		}
+
+		if result.Status == StatusSkipped || result.Status == StatusDryRun {
+			c.Status = StatusSkipped
+			report.Checks = append(report.Checks, c)
+			continue
+		}
+
+		if c.GotExit != c.WantExit {
+			c.ExitOK = false
+			c.Message = fmt.Sprintf("exit code %d, recorded %d", c.GotExit, c.WantExit)
+		}
+		if !durationWithin(c.WantMs, c.GotMs, tol) {
+			c.DurationOK = false
+			msg := fmt.Sprintf("took %s, recorded %s", ms(c.GotMs), ms(c.WantMs))
+			if c.Message != "" {
+				msg = c.Message + "; " + msg
+			}
+			c.Message = msg
+		}
+		report.Checks = append(report.Checks, c)
+	}
+	return report
+}
+
+func durationWithin(wantMs, gotMs int64, tol Tolerance) bool {
+	if tol.Factor <= 0 || wantMs <= 0 {
+		return true
+	}
+	want := time.Duration(wantMs) * time.Millisecond
+	got := time.Duration(gotMs) * time.Millisecond
+	diff := got - want
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff <= tol.Slack {
+		return true
+	}
+	return float64(got) <= float64(want)*tol.Factor && float64(got)*tol.Factor >= float64(want)
+}
+
+func ms(v int64) string {
+	return (time.Duration(v) * time.Millisecond).String()
+}
+
+// WriteText writes a human-readable pass/fail table.
+func (r Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tRESULT\tEXIT\tDURATION\tCOMMAND")
+	for _, c := range r.Checks {
+		result := "PASS"
+		switch {
+		case c.Status == StatusSkipped:
+			result = "SKIP"
+		case !c.Passed():
+			result = "FAIL"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d (want %d)\t%s (want %s)\t%s\n", c.Step, result, c.GotExit, c.WantExit, ms(c.GotMs), ms(c.WantMs), c.Cmd)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	for _, c := range r.Checks {
+		if c.Status != StatusSkipped && !c.Passed() {
+			fmt.Fprintf(w, "step %d: %s\n", c.Step, c.Message)
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d of %d steps failed verification\n", r.Failures(), len(r.Checks))
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML with one test case per step.
+func (r Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:     "cmdsetgo." + r.Selection,
+		Tests:    len(r.Checks),
+		Failures: r.Failures(),
+		Time:     seconds(r.Duration),
+	}
+	for _, c := range r.Checks {
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("step %d: %s", c.Step, c.Cmd),
+			Classname: suite.Name,
+			Time:      seconds(time.Duration(c.GotMs) * time.Millisecond),
+		}
+		switch {
+		case c.Status == StatusSkipped:
+			tc.Skipped = &struct{}{}
+			suite.Skipped++
+		case !c.Passed():
+			tc.Failure = &junitFailure{Message: c.Message, Body: c.Cmd}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+func TestVerify(t *testing.T) {
+	sel := pick.Selection{
+		ID: "20240102-030405",
+		Items: pick.NewItems([]events.CmdEvent{
+			{Cmd: "make", Exit: 0, DurationMs: 1000},
+			{Cmd: "make test", Exit: 2},
+			{Cmd: "make slow", Exit: 0, DurationMs: 1000},
+			{Cmd: "make deploy", Exit: 0},
+		}),
+	}
+	log := Log{Steps: []StepResult{
+		{Step: 1, Status: StatusOK, CmdEvent: events.CmdEvent{Exit: 0, DurationMs: 1400}},
+		{Step: 2, Status: StatusFailed, CmdEvent: events.CmdEvent{Exit: 2, DurationMs: 10}},
+		{Step: 3, Status: StatusOK, CmdEvent: events.CmdEvent{Exit: 0, DurationMs: 9000}},
+		{Step: 4, Status: StatusSkipped},
+	}}
+
+	report := Verify(sel, log, Tolerance{Factor: 2, Slack: 500 * time.Millisecond})
+
+	want := []bool{true, true, false, false}
+	for i, c := range report.Checks {
+		if c.Passed() != want[i] {
+			t.Errorf("step %d passed = %v, want %v (%s)", c.Step, c.Passed(), want[i], c.Message)
+		}
+	}
+	if report.Failures() != 1 {
+		t.Errorf("Failures() = %d, want 1", report.Failures())
+	}
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "step 3: took 9s, recorded 1s") {
+		t.Errorf("text report missing failure detail:\n%s", text.String())
+	}
+
+	var junit bytes.Buffer
+	if err := report.WriteJUnit(&junit); err != nil {
+		t.Fatal(err)
+	}
+	var parsed junitTestSuites
+	if err := xml.Unmarshal(junit.Bytes(), &parsed); err != nil {
+		t.Fatalf("invalid JUnit XML: %v\n%s", err, junit.String())
+	}
+	suite := parsed.Suites[0]
+	if suite.Tests != 4 || suite.Failures != 1 || suite.Skipped != 1 || suite.Cases[2].Failure == nil {
+		t.Errorf("unexpected JUnit suite: %+v", suite)
+	}
+}
+
+func TestVerifyExitMismatch(t *testing.T) {
+	sel := pick.Selection{ID: "x", Items: pick.NewItems([]events.CmdEvent{{Cmd: "go test", Exit: 0}})}
+	log := Log{Steps: []StepResult{{Step: 1, Status: StatusFailed, CmdEvent: events.CmdEvent{Exit: 1}}}}
+
+	report := Verify(sel, log, Tolerance{})
+	if c := report.Checks[0]; c.Passed() || c.ExitOK || c.Message != "exit code 1, recorded 0" {
+		t.Errorf("check = %+v", c)
+	}
+}
+
+func TestToleranceValidate(t *testing.T) {
+	tests := []struct {
+		tol Tolerance
+		ok  bool
+	}{
+		{Tolerance{}, true},
+		{Tolerance{Factor: 1}, true},
+		{Tolerance{Factor: 1.5, Slack: time.Second}, true},
+		{Tolerance{Factor: 0.5}, false},
+		{Tolerance{Factor: -2}, false},
+		{Tolerance{Factor: 2, Slack: -time.Second}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.tol.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%+v.Validate() = %v, want ok %v", tt.tol, err, tt.ok)
+		}
+	}
+}
@@ -202,3 +202,8 @@ func Uninstall(shellName string) error {
 
 	return os.WriteFile(rcPath, []byte(strings.Join(newLines, "\n")), 0644)
 }
+
+// Quote quotes s as a single word for POSIX shells, using single quotes.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,93 @@
+package tui
+
+import "unicode/utf8"
+
+type KeyKind int
+
+const (
+	KeyRune KeyKind = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyCtrlC
+	KeyCtrlE
+	KeyCtrlU
+)
+
+// Key is a single decoded keypress. Rune is only set for KeyRune.
+type Key struct {
+	Kind KeyKind
+	Rune rune
+}
+
+// arrowKeys maps the final byte of a CSI or SS3 sequence to its key.
+var arrowKeys = map[byte]KeyKind{'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft}
+
+// DecodeKeys turns a chunk of raw terminal input into keypresses.
+// A lone ESC byte is reported as KeyEsc; unknown escape sequences are dropped.
+func DecodeKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b && len(b) >= 2 && b[1] == '[':
+			// CSI: parameter and intermediate bytes, then a final byte,
+			// e.g. "\x1b[A" or "\x1b[3~" (Delete).
+			end := 2
+			for end < len(b) && b[end] >= 0x20 && b[end] <= 0x3f {
+				end++
+			}
+			if end < len(b) && b[end] >= 0x40 && b[end] <= 0x7e {
+				if kind, ok := arrowKeys[b[end]]; ok {
+					keys = append(keys, Key{Kind: kind})
+				}
+				end++
+			}
+			b = b[end:]
+		case c == 0x1b && len(b) >= 3 && b[1] == 'O':
+			// SS3: a single final byte.
+			if kind, ok := arrowKeys[b[2]]; ok {
+				keys = append(keys, Key{Kind: kind})
+			}
+			b = b[3:]
+		case c == 0x1b:
+			keys = append(keys, Key{Kind: KeyEsc})
+			b = b[1:]
+		case c == '\r' || c == '\n':
+			keys = append(keys, Key{Kind: KeyEnter})
+			b = b[1:]
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, Key{Kind: KeyBackspace})
+			b = b[1:]
+		case c == '\t':
+			keys = append(keys, Key{Kind: KeyTab})
+			b = b[1:]
+		case c == 0x03:
+			keys = append(keys, Key{Kind: KeyCtrlC})
+			b = b[1:]
+		case c == 0x05:
+			keys = append(keys, Key{Kind: KeyCtrlE})
+			b = b[1:]
+		case c == 0x15:
+			keys = append(keys, Key{Kind: KeyCtrlU})
+			b = b[1:]
+		case c == 0x10:
+			keys = append(keys, Key{Kind: KeyUp})
+			b = b[1:]
+		case c == 0x0e:
+			keys = append(keys, Key{Kind: KeyDown})
+			b = b[1:]
+		case c < 0x20:
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, Key{Kind: KeyRune, Rune: r})
+			b = b[size:]
+		}
+	}
+	return keys
+}
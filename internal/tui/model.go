@@ -0,0 +1,453 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+	"github.com/drakeafk/cmdsetgo/internal/scope"
+)
+
+type focus int
+
+const (
+	focusList focus = iota
+	focusChosen
+)
+
+// editField is the value the inline line editor is changing.
+type editField int
+
+const (
+	editCmd editField = iota
+	editTitle
+	editNote
+	editSection
+)
+
+func (f editField) label() string {
+	switch f {
+	case editTitle:
+		return "Title"
+	case editNote:
+		return "Note"
+	case editSection:
+		return "Section"
+	}
+	return "Edit"
+}
+
+// Model holds the picker state. It is driven purely by HandleKey so the
+// behaviour can be exercised without a terminal.
+type Model struct {
+	entries  []events.CmdEvent
+	repoRoot string
+
+	query   string
+	visible []int // indices into entries matching query
+	cursor  int   // position in visible
+
+	chosen       []int // indices into entries, in export order
+	chosenCursor int
+	focus        focus
+	annotations  map[int]pick.Item // title/note/section by entry index
+
+	editing    bool
+	editField  editField
+	editTarget int
+	editBuf    []rune
+	editPos    int
+
+	done      bool
+	cancelled bool
+}
+
+// NewModel creates a picker over evs. The events are copied so inline edits
+// never touch the caller's slice.
+func NewModel(evs []events.CmdEvent, repoRoot string) *Model {
+	m := &Model{
+		entries:     append([]events.CmdEvent(nil), evs...),
+		repoRoot:    repoRoot,
+		annotations: make(map[int]pick.Item),
+	}
+	m.refilter()
+	// Start on the most recent command, like the bottom of `last`.
+	m.cursor = len(m.visible) - 1
+	return m
+}
+
+// Done reports whether the user confirmed or cancelled the picker.
+func (m *Model) Done() bool {
+	return m.done || m.cancelled
+}
+
+// Selection returns the chosen items in order, with inline edits and
+// annotations applied. It returns nil if the picker was cancelled.
+func (m *Model) Selection() []pick.Item {
+	if m.cancelled {
+		return nil
+	}
+	var items []pick.Item
+	for _, idx := range m.chosen {
+		item := m.annotations[idx]
+		item.CmdEvent = m.entries[idx]
+		items = append(items, item)
+	}
+	return items
+}
+
+// HandleKey applies a single keypress to the model.
+func (m *Model) HandleKey(k Key) {
+	if k.Kind == KeyCtrlC {
+		m.cancelled = true
+		return
+	}
+	if m.editing {
+		m.handleEditKey(k)
+		return
+	}
+
+	switch k.Kind {
+	case KeyEsc:
+		m.cancelled = true
+		return
+	case KeyTab:
+		if m.focus == focusList && len(m.chosen) > 0 {
+			m.focus = focusChosen
+		} else {
+			m.focus = focusList
+		}
+		return
+	case KeyEnter:
+		if len(m.chosen) == 0 && m.current() >= 0 {
+			m.chosen = append(m.chosen, m.current())
+		}
+		m.done = true
+		return
+	case KeyCtrlE:
+		m.startEdit(m.current(), editCmd)
+		return
+	}
+
+	if m.focus == focusChosen {
+		m.handleChosenKey(k)
+	} else {
+		m.handleListKey(k)
+	}
+}
+
+func (m *Model) handleListKey(k Key) {
+	switch k.Kind {
+	case KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case KeyDown:
+		if m.cursor < len(m.visible)-1 {
+			m.cursor++
+		}
+	case KeyBackspace:
+		if r := []rune(m.query); len(r) > 0 {
+			m.query = string(r[:len(r)-1])
+			m.refilter()
+		}
+	case KeyCtrlU:
+		m.query = ""
+		m.refilter()
+	case KeyRune:
+		if k.Rune == ' ' {
+			m.toggle(m.current())
+			return
+		}
+		m.query += string(k.Rune)
+		m.refilter()
+	}
+}
+
+func (m *Model) handleChosenKey(k Key) {
+	switch k.Kind {
+	case KeyUp:
+		if m.chosenCursor > 0 {
+			m.chosenCursor--
+		}
+	case KeyDown:
+		if m.chosenCursor < len(m.chosen)-1 {
+			m.chosenCursor++
+		}
+	case KeyBackspace:
+		m.removeChosen(m.chosenCursor)
+	case KeyRune:
+		switch k.Rune {
+		case 'k':
+			if m.chosenCursor > 0 {
+				m.chosenCursor--
+			}
+		case 'j':
+			if m.chosenCursor < len(m.chosen)-1 {
+				m.chosenCursor++
+			}
+		case 'K', '-':
+			m.moveChosen(-1)
+		case 'J', '+':
+			m.moveChosen(1)
+		case 'x', ' ':
+			m.removeChosen(m.chosenCursor)
+		case 'e':
+			m.startEdit(m.current(), editCmd)
+		case 't':
+			m.startEdit(m.current(), editTitle)
+		case 'n':
+			m.startEdit(m.current(), editNote)
+		case 's':
+			m.startEdit(m.current(), editSection)
+		}
+	}
+}
+
+func (m *Model) handleEditKey(k Key) {
+	switch k.Kind {
+	case KeyEsc:
+		m.editing = false
+	case KeyEnter:
+		m.applyEdit(strings.TrimSpace(string(m.editBuf)))
+		m.editing = false
+	case KeyLeft:
+		if m.editPos > 0 {
+			m.editPos--
+		}
+	case KeyRight:
+		if m.editPos < len(m.editBuf) {
+			m.editPos++
+		}
+	case KeyBackspace:
+		if m.editPos > 0 {
+			m.editBuf = append(m.editBuf[:m.editPos-1], m.editBuf[m.editPos:]...)
+			m.editPos--
+		}
+	case KeyCtrlU:
+		m.editBuf = m.editBuf[m.editPos:]
+		m.editPos = 0
+	case KeyRune:
+		m.editBuf = append(m.editBuf[:m.editPos], append([]rune{k.Rune}, m.editBuf[m.editPos:]...)...)
+		m.editPos++
+	}
+}
+
+// current returns the entry index under the cursor of the focused pane, or -1.
+func (m *Model) current() int {
+	if m.focus == focusChosen {
+		if m.chosenCursor < len(m.chosen) {
+			return m.chosen[m.chosenCursor]
+		}
+		return -1
+	}
+	if m.cursor >= 0 && m.cursor < len(m.visible) {
+		return m.visible[m.cursor]
+	}
+	return -1
+}
+
+func (m *Model) refilter() {
+	m.visible = pick.FuzzyFilter(m.entries, m.query)
+	if m.query != "" {
+		m.cursor = 0
+	} else {
+		m.cursor = len(m.visible) - 1
+	}
+}
+
+func (m *Model) toggle(idx int) {
+	if idx < 0 {
+		return
+	}
+	for pos, c := range m.chosen {
+		if c == idx {
+			m.removeChosen(pos)
+			return
+		}
+	}
+	m.chosen = append(m.chosen, idx)
+}
+
+func (m *Model) removeChosen(pos int) {
+	if pos < 0 || pos >= len(m.chosen) {
+		return
+	}
+	m.chosen = append(m.chosen[:pos], m.chosen[pos+1:]...)
+	if m.chosenCursor >= len(m.chosen) && m.chosenCursor > 0 {
+		m.chosenCursor--
+	}
+	if len(m.chosen) == 0 {
+		m.focus = focusList
+	}
+}
+
+func (m *Model) moveChosen(delta int) {
+	from := m.chosenCursor
+	to := from + delta
+	if to < 0 || to >= len(m.chosen) {
+		return
+	}
+	m.chosen[from], m.chosen[to] = m.chosen[to], m.chosen[from]
+	m.chosenCursor = to
+}
+
+func (m *Model) startEdit(idx int, field editField) {
+	if idx < 0 {
+		return
+	}
+	var value string
+	a := m.annotations[idx]
+	switch field {
+	case editCmd:
+		value = m.entries[idx].Cmd
+	case editTitle:
+		value = a.Title
+	case editNote:
+		value = a.Note
+	case editSection:
+		value = a.Section
+	}
+
+	m.editing = true
+	m.editField = field
+	m.editTarget = idx
+	m.editBuf = []rune(value)
+	m.editPos = len(m.editBuf)
+}
+
+// applyEdit stores the edited value. An empty command is ignored, while an
+// empty annotation clears it.
+func (m *Model) applyEdit(value string) {
+	a := m.annotations[m.editTarget]
+	switch m.editField {
+	case editCmd:
+		if value != "" {
+			m.entries[m.editTarget].Cmd = value
+		}
+		return
+	case editTitle:
+		a.Title = value
+	case editNote:
+		a.Note = value
+	case editSection:
+		a.Section = value
+	}
+	m.annotations[m.editTarget] = a
+}
+
+func (m *Model) chosenPos(idx int) int {
+	for pos, c := range m.chosen {
+		if c == idx {
+			return pos + 1
+		}
+	}
+	return 0
+}
+
+// Render returns the screen contents as lines no wider than cols.
+func (m *Model) Render(rows, cols int) []string {
+	const previewLines = 4
+	chosenLines := len(m.chosen) + 1
+	if chosenLines > rows/3 {
+		chosenLines = rows / 3
+	}
+	listLines := rows - previewLines - chosenLines - 4
+	if listLines < 1 {
+		listLines = 1
+	}
+
+	var lines []string
+	prompt := "Filter: " + m.query
+	if m.focus == focusList && !m.editing {
+		prompt += "_"
+	}
+	lines = append(lines, prompt)
+
+	// Keep the cursor inside the visible window.
+	start := 0
+	if m.cursor >= listLines {
+		start = m.cursor - listLines + 1
+	}
+	for i := start; i < len(m.visible) && i < start+listLines; i++ {
+		idx := m.visible[i]
+		ev := m.entries[idx]
+		pointer := "  "
+		if i == m.cursor && m.focus == focusList {
+			pointer = "> "
+		}
+		mark := "[ ]"
+		if pos := m.chosenPos(idx); pos > 0 {
+			mark = fmt.Sprintf("[%d]", pos)
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s  %s", pointer, mark, ev.Ts.Local().Format("15:04:05"), ev.Label()))
+	}
+	for len(lines) < listLines+1 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, strings.Repeat("─", cols))
+	lines = append(lines, m.preview()...)
+
+	lines = append(lines, fmt.Sprintf("Selected (%d):", len(m.chosen)))
+	cstart := 0
+	if m.chosenCursor >= chosenLines-1 && chosenLines > 1 {
+		cstart = m.chosenCursor - chosenLines + 2
+	}
+	for pos := cstart; pos < len(m.chosen) && pos < cstart+chosenLines-1; pos++ {
+		pointer := "  "
+		if pos == m.chosenCursor && m.focus == focusChosen {
+			pointer = "> "
+		}
+		idx := m.chosen[pos]
+		label := m.entries[idx].Label()
+		a := m.annotations[idx]
+		if a.Title != "" {
+			label = a.Title + " — " + label
+		}
+		if a.Section != "" {
+			label = "[" + a.Section + "] " + label
+		}
+		lines = append(lines, fmt.Sprintf("%s%d. %s", pointer, pos+1, label))
+	}
+
+	if m.editing {
+		buf := string(m.editBuf[:m.editPos]) + "_" + string(m.editBuf[m.editPos:])
+		lines = append(lines, m.editField.label()+": "+buf)
+	} else if m.focus == focusChosen {
+		lines = append(lines, "↑/↓ cursor  K/J reorder  x remove  e edit  t title  n note  s section  tab list  enter save  esc cancel")
+	} else {
+		lines = append(lines, "type to filter  ↑/↓ move  space select  ctrl-e edit  tab selected  enter save  esc cancel")
+	}
+
+	for i, l := range lines {
+		if r := []rune(l); len(r) > cols {
+			lines[i] = string(r[:cols])
+		}
+	}
+	return lines
+}
+
+func (m *Model) preview() []string {
+	idx := m.current()
+	if idx < 0 {
+		return []string{"", "", "", ""}
+	}
+	ev := m.entries[idx]
+	duration := "-"
+	if ev.DurationMs > 0 {
+		duration = (time.Duration(ev.DurationMs) * time.Millisecond).String()
+	}
+	timeLine := "Time:     " + ev.Ts.Local().Format(time.RFC1123)
+	if note := m.annotations[idx].Note; note != "" {
+		timeLine += "  Note: " + note
+	}
+	return []string{
+		"Cwd:      " + ev.Cwd + " (" + scope.FormatCwd(ev.Cwd, m.repoRoot) + ")",
+		fmt.Sprintf("Exit:     %d", ev.Exit),
+		"Duration: " + duration,
+		timeLine,
+	}
+}
@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+func runes(s string) []Key {
+	var keys []Key
+	for _, r := range s {
+		keys = append(keys, Key{Kind: KeyRune, Rune: r})
+	}
+	return keys
+}
+
+func cmds(items []pick.Item) []string {
+	var out []string
+	for _, item := range items {
+		out = append(out, item.Cmd)
+	}
+	return out
+}
+
+func TestDecodeKeys(t *testing.T) {
+	got := DecodeKeys([]byte("a \x1b[A\x1b[3~x\x1b[1;5B\x1bOC\r\x7f\x1b\t\x05"))
+	want := []Key{
+		{Kind: KeyRune, Rune: 'a'},
+		{Kind: KeyRune, Rune: ' '},
+		{Kind: KeyUp},
+		{Kind: KeyRune, Rune: 'x'},
+		{Kind: KeyDown},
+		{Kind: KeyRight},
+		{Kind: KeyEnter},
+		{Kind: KeyBackspace},
+		{Kind: KeyEsc},
+		{Kind: KeyTab},
+		{Kind: KeyCtrlE},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestModelSelectReorderEdit(t *testing.T) {
+	evs := []events.CmdEvent{
+		{Cmd: "go build ./..."},
+		{Cmd: "go test ./..."},
+		{Cmd: "make lint"},
+	}
+	m := NewModel(evs, "")
+
+	var keys []Key
+	keys = append(keys, runes("lint")...)
+	keys = append(keys, Key{Kind: KeyRune, Rune: ' '}) // select "make lint"
+	keys = append(keys, Key{Kind: KeyCtrlU})
+	keys = append(keys, Key{Kind: KeyUp}, Key{Kind: KeyUp}, Key{Kind: KeyRune, Rune: ' '}) // select "go build"
+	keys = append(keys, Key{Kind: KeyTab}, Key{Kind: KeyDown}, Key{Kind: KeyRune, Rune: 'K'})
+	keys = append(keys, Key{Kind: KeyRune, Rune: 'e'})
+	keys = append(keys, runes(" -v")...)
+	keys = append(keys, Key{Kind: KeyEnter}) // accept edit
+	keys = append(keys, Key{Kind: KeyEnter}) // save
+
+	for _, k := range keys {
+		m.HandleKey(k)
+	}
+
+	if !m.Done() {
+		t.Fatal("expected model to be done")
+	}
+	want := []string{"go build ./... -v", "make lint"}
+	if got := cmds(m.Selection()); !reflect.DeepEqual(got, want) {
+		t.Errorf("Selection() = %v, want %v", got, want)
+	}
+	if evs[0].Cmd != "go build ./..." {
+		t.Errorf("inline edit modified caller's events: %q", evs[0].Cmd)
+	}
+}
+
+func TestModelCancel(t *testing.T) {
+	m := NewModel([]events.CmdEvent{{Cmd: "ls"}}, "")
+	m.HandleKey(Key{Kind: KeyRune, Rune: ' '})
+	m.HandleKey(Key{Kind: KeyEsc})
+	if !m.Done() || m.Selection() != nil {
+		t.Errorf("expected cancelled model with no selection")
+	}
+}
+
+func TestModelAnnotate(t *testing.T) {
+	m := NewModel([]events.CmdEvent{{Cmd: "make deps"}}, "")
+
+	keys := []Key{{Kind: KeyRune, Rune: ' '}, {Kind: KeyTab}, {Kind: KeyRune, Rune: 't'}}
+	keys = append(keys, runes("Install dependencies")...)
+	keys = append(keys, Key{Kind: KeyEnter}, Key{Kind: KeyRune, Rune: 's'})
+	keys = append(keys, runes("Setup")...)
+	keys = append(keys, Key{Kind: KeyEnter}, Key{Kind: KeyEnter})
+	for _, k := range keys {
+		m.HandleKey(k)
+	}
+
+	items := m.Selection()
+	if len(items) != 1 || items[0].Title != "Install dependencies" || items[0].Section != "Setup" || items[0].Cmd != "make deps" {
+		t.Errorf("Selection() = %+v", items)
+	}
+}
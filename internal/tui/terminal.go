@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/drakeafk/cmdsetgo/internal/events"
+	"github.com/drakeafk/cmdsetgo/internal/pick"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	exitAltScreen  = "\x1b[?25h\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// Available reports whether both stdin and stdout are terminals and stty is
+// installed, which is all the full-screen picker needs.
+func Available() bool {
+	if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+		return false
+	}
+	_, err := exec.LookPath("stty")
+	return err == nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Run shows the full-screen picker over evs and returns the chosen items in
+// order. It returns nil without error if the user cancels.
+func Run(evs []events.CmdEvent, repoRoot string) ([]pick.Item, error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terminal state: %w", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	defer stty(saved)
+
+	out := os.Stdout
+	fmt.Fprint(out, enterAltScreen)
+	defer fmt.Fprint(out, exitAltScreen)
+
+	m := NewModel(evs, repoRoot)
+	// mu guards the model and the screen, which a resize redraws while the
+	// loop below waits for input.
+	var mu sync.Mutex
+	rows, cols := terminalSize()
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range resized {
+			mu.Lock()
+			rows, cols = terminalSize()
+			draw(out, m.Render(rows, cols))
+			mu.Unlock()
+		}
+	}()
+	defer func() {
+		signal.Stop(resized)
+		close(resized)
+		<-done
+	}()
+
+	buf := make([]byte, 64)
+	for {
+		mu.Lock()
+		if m.Done() {
+			selection := m.Selection()
+			mu.Unlock()
+			return selection, nil
+		}
+		draw(out, m.Render(rows, cols))
+		mu.Unlock()
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		for _, k := range DecodeKeys(buf[:n]) {
+			m.HandleKey(k)
+			if m.Done() {
+				break
+			}
+		}
+		mu.Unlock()
+	}
+}
+
+func draw(w io.Writer, lines []string) {
+	// Raw mode disables output post-processing, so lines need explicit \r.
+	fmt.Fprint(w, clearScreen+strings.Join(lines, "\r\n"))
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// terminalSize returns the terminal dimensions, defaulting to 24x80. It runs
+// stty, so Run calls it once and again when the terminal is resized.
+func terminalSize() (int, int) {
+	size, err := stty("size")
+	if err == nil {
+		var rows, cols int
+		if _, err := fmt.Sscanf(size, "%d %d", &rows, &cols); err == nil && rows > 0 && cols > 0 {
+			return rows, cols
+		}
+	}
+	return 24, 80
+}